@@ -431,9 +431,9 @@ func (g *RuntimeEnvGenerator) runtimeConfigForGateway(hostnames []string) (strin
 
 func (g *RuntimeEnvGenerator) baseGracefulShutdown() *config.GracefulShutdownTimings {
 	return &config.GracefulShutdownTimings{
-		Total:         g.GracefulShutdownTime.PtrOrNil(),
-		ShutdownHooks: g.ShutdownHooksGrace.PtrOrNil(),
-		Handlers:      g.HandlersGrace.PtrOrNil(),
+		Total:         (*config.Duration)(g.GracefulShutdownTime.PtrOrNil()),
+		ShutdownHooks: (*config.Duration)(g.ShutdownHooksGrace.PtrOrNil()),
+		Handlers:      (*config.Duration)(g.HandlersGrace.PtrOrNil()),
 	}
 }
 
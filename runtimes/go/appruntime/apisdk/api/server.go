@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/benbjohnson/clock"
 	jsoniter "github.com/json-iterator/go"
@@ -194,6 +195,11 @@ func NewServer(static *config.Static, runtime *config.Runtime, rt *reqtrack.Requ
 		)
 	}
 
+	// Cancel the request context once the configured request timeout elapses
+	if timeout, ok := runtime.RequestTimeout(); ok {
+		baseHandler = withRequestTimeout(baseHandler, timeout)
+	}
+
 	// Finally, this handler is used to track the number of running handlers
 	// on the server so we can wait for them to finish before shutting down
 	//
@@ -359,6 +365,16 @@ func (s *Server) Shutdown(p *shutdown.Process) error {
 	return <-shutdownErr
 }
 
+// withRequestTimeout wraps h so that each request's context
+// is canceled after the given timeout.
+func withRequestTimeout(h http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		h.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
 func (s *Server) handler(w http.ResponseWriter, req *http.Request) {
 	// Select a router based on access
 	router, fallbackRouter := s.public, s.publicFallback
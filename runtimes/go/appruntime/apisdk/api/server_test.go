@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 )
@@ -82,3 +83,21 @@ func Test_determineRequestPath(t *testing.T) {
 		}
 	}
 }
+
+func Test_withRequestTimeout(t *testing.T) {
+	var deadline time.Time
+	var hasDeadline bool
+	h := withRequestTimeout(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		deadline, hasDeadline = req.Context().Deadline()
+	}), time.Minute)
+
+	start := time.Now()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	end := time.Now()
+	if !hasDeadline {
+		t.Fatal("request context has no deadline")
+	}
+	if deadline.Before(start.Add(time.Minute)) || deadline.After(end.Add(time.Minute)) {
+		t.Errorf("got deadline %v, want one minute after the request started", deadline)
+	}
+}
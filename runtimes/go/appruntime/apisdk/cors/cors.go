@@ -52,6 +52,11 @@ func Options(cfg *config.CORS, staticAllowedHeaders, staticExposedHeaders []stri
 	exposedHeaders = append(exposedHeaders, cfg.ExtraExposedHeaders...)
 	exposedHeaders = append(exposedHeaders, staticExposedHeaders...)
 
+	allowedMethods := []string{"GET", "POST", "PUT", "PATCH", "HEAD", "DELETE", "OPTIONS", "TRACE", "CONNECT"}
+	if cfg.AllowedMethods != nil {
+		allowedMethods = cfg.AllowedMethods
+	}
+
 	// Sort the slices so the output looks nicer.
 	sort.Strings(allowedHeaders)
 	sort.Strings(exposedHeaders)
@@ -59,7 +64,7 @@ func Options(cfg *config.CORS, staticAllowedHeaders, staticExposedHeaders []stri
 	return cors.Options{
 		Debug:               cfg.Debug,
 		AllowCredentials:    !cfg.DisableCredentials,
-		AllowedMethods:      []string{"GET", "POST", "PUT", "PATCH", "HEAD", "DELETE", "OPTIONS", "TRACE", "CONNECT"},
+		AllowedMethods:      allowedMethods,
 		AllowedHeaders:      allowedHeaders,
 		ExposedHeaders:      exposedHeaders,
 		AllowPrivateNetwork: cfg.AllowPrivateNetworkAccess,
@@ -0,0 +1,41 @@
+package config
+
+import "fmt"
+
+// minAuthKeyLength is the minimum length of the key material of an
+// auth key. The keys generated by Encore are 16 random bytes.
+const minAuthKeyLength = 16
+
+// validateAuthKeys checks that the auth keys, both the app's own
+// and the ones for Encore Cloud, are well-formed and unique.
+func (r *Runtime) validateAuthKeys() []error {
+	var errs []error
+	check := func(kind string, keyID uint32, data []byte, seen map[uint32]bool) {
+		switch {
+		case keyID == 0:
+			errs = append(errs, fmt.Errorf("%s 0: key id must be positive", kind))
+		case seen[keyID]:
+			errs = append(errs, fmt.Errorf("%s %d: defined more than once", kind, keyID))
+		}
+		seen[keyID] = true
+
+		if len(data) == 0 {
+			errs = append(errs, fmt.Errorf("%s %d: missing key data", kind, keyID))
+		} else if len(data) < minAuthKeyLength {
+			errs = append(errs, fmt.Errorf("%s %d: key data is %d bytes, want at least %d",
+				kind, keyID, len(data), minAuthKeyLength))
+		}
+	}
+
+	seen := make(map[uint32]bool, len(r.AuthKeys))
+	for _, k := range r.AuthKeys {
+		check("auth key", k.KeyID, k.Data, seen)
+	}
+	if r.EncoreCloudAPI != nil {
+		seen := make(map[uint32]bool, len(r.EncoreCloudAPI.AuthKeys))
+		for _, k := range r.EncoreCloudAPI.AuthKeys {
+			check("encore cloud auth key", k.KeyID, k.Data, seen)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.encore.dev/platform-sdk/pkg/auth"
+)
+
+func TestValidateAuthKeys(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	tests := []struct {
+		name    string
+		modify  func(cfg *Runtime)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Runtime) {},
+		},
+		{
+			name: "empty_material",
+			modify: func(cfg *Runtime) {
+				cfg.AuthKeys = append(cfg.AuthKeys, EncoreAuthKey{KeyID: 7})
+			},
+			wantErr: "auth key 7: missing key data",
+		},
+		{
+			name: "short_material",
+			modify: func(cfg *Runtime) {
+				cfg.AuthKeys[0].Data = []byte("short")
+			},
+			wantErr: "auth key 1: key data is 5 bytes, want at least 16",
+		},
+		{
+			name: "zero_key_id",
+			modify: func(cfg *Runtime) {
+				cfg.AuthKeys[0].KeyID = 0
+			},
+			wantErr: "auth key 0: key id must be positive",
+		},
+		{
+			name: "duplicate_key_id",
+			modify: func(cfg *Runtime) {
+				cfg.AuthKeys = append(cfg.AuthKeys, EncoreAuthKey{KeyID: 1, Data: key})
+			},
+			wantErr: "auth key 1: defined more than once",
+		},
+		{
+			name: "encore_cloud",
+			modify: func(cfg *Runtime) {
+				cfg.EncoreCloudAPI.AuthKeys = append(cfg.EncoreCloudAPI.AuthKeys, auth.Key{KeyID: 2, Data: key})
+			},
+			wantErr: "encore cloud auth key 2: defined more than once",
+		},
+		{
+			name: "same_id_across_kinds",
+			modify: func(cfg *Runtime) {
+				cfg.EncoreCloudAPI.AuthKeys[0].KeyID = cfg.AuthKeys[0].KeyID
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fullRuntime()
+			tt.modify(cfg)
+			err := errors.Join(cfg.validateAuthKeys()...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RuntimeBuilder builds a runtime config step by step.
+// Create one using [NewRuntimeBuilder] or [EditRuntime].
+//
+// Servers and providers are given ids in the order they are added,
+// starting at zero. References to them are checked as they're made,
+// and any problems are reported by [RuntimeBuilder.Build].
+type RuntimeBuilder struct {
+	cfg  *Runtime
+	errs []error
+}
+
+// NewRuntimeBuilder returns a builder for the runtime config of the given app.
+func NewRuntimeBuilder(appID string) *RuntimeBuilder {
+	return &RuntimeBuilder{cfg: &Runtime{AppID: appID, AppSlug: appID}}
+}
+
+// EditRuntime returns a builder that modifies cfg in place.
+// New servers and providers are given ids following the existing ones.
+func EditRuntime(cfg *Runtime) *RuntimeBuilder {
+	return &RuntimeBuilder{cfg: cfg}
+}
+
+// WithAPIBaseURL sets the API base URL.
+func (b *RuntimeBuilder) WithAPIBaseURL(apiBaseURL string) *RuntimeBuilder {
+	b.cfg.APIBaseURL = apiBaseURL
+	return b
+}
+
+// WithEnv sets the environment name, type and cloud.
+func (b *RuntimeBuilder) WithEnv(name, envType, envCloud string) *RuntimeBuilder {
+	b.cfg.EnvName = name
+	b.cfg.EnvType = envType
+	b.cfg.EnvCloud = envCloud
+	return b
+}
+
+// WithDeployID sets the deploy ID.
+func (b *RuntimeBuilder) WithDeployID(deployID string) *RuntimeBuilder {
+	b.cfg.DeployID = deployID
+	return b
+}
+
+// AddAuthKey adds a service-to-service auth key.
+func (b *RuntimeBuilder) AddAuthKey(id uint32, data []byte) *RuntimeBuilder {
+	b.cfg.AuthKeys = append(b.cfg.AuthKeys, EncoreAuthKey{KeyID: id, Data: data})
+	return b
+}
+
+// AddSQLServer adds a SQL server. Its server id is the number of
+// SQL servers added before it.
+func (b *RuntimeBuilder) AddSQLServer(srv SQLServer) *RuntimeBuilder {
+	b.cfg.SQLServers = append(b.cfg.SQLServers, &srv)
+	return b
+}
+
+// AddSQLDatabase adds a SQL database on the SQL server with the given id,
+// with the same database name on the server as its Encore name.
+func (b *RuntimeBuilder) AddSQLDatabase(serverID int, name, user, password string) *RuntimeBuilder {
+	if serverID < 0 || serverID >= len(b.cfg.SQLServers) {
+		b.errs = append(b.errs, fmt.Errorf("sql database %q: unknown server id %d", name, serverID))
+		return b
+	}
+	b.cfg.SQLDatabases = append(b.cfg.SQLDatabases, &SQLDatabase{
+		ServerID:     serverID,
+		EncoreName:   name,
+		DatabaseName: name,
+		User:         user,
+		Password:     password,
+	})
+	return b
+}
+
+// AddRedisServer adds a Redis server. Its server id is the number of
+// Redis servers added before it.
+func (b *RuntimeBuilder) AddRedisServer(srv RedisServer) *RuntimeBuilder {
+	b.cfg.RedisServers = append(b.cfg.RedisServers, &srv)
+	return b
+}
+
+// AddRedisDatabase adds a Redis database using the given database index
+// on the Redis server with the given id.
+func (b *RuntimeBuilder) AddRedisDatabase(serverID int, name string, database int) *RuntimeBuilder {
+	if serverID < 0 || serverID >= len(b.cfg.RedisServers) {
+		b.errs = append(b.errs, fmt.Errorf("redis database %q: unknown server id %d", name, serverID))
+		return b
+	}
+	b.cfg.RedisDatabases = append(b.cfg.RedisDatabases, &RedisDatabase{
+		ServerID:   serverID,
+		EncoreName: name,
+		Database:   database,
+	})
+	return b
+}
+
+// AddPubsubProvider adds a pubsub provider. Its provider id is the
+// number of pubsub providers added before it.
+func (b *RuntimeBuilder) AddPubsubProvider(p PubsubProvider) *RuntimeBuilder {
+	b.cfg.PubsubProviders = append(b.cfg.PubsubProviders, &p)
+	return b
+}
+
+// AddPubsubTopic adds a pubsub topic using the pubsub provider with
+// the given id, along with the given subscriptions to it. The names
+// of the topic and subscriptions are used as their provider names too.
+func (b *RuntimeBuilder) AddPubsubTopic(providerID int, name string, subscriptions ...string) *RuntimeBuilder {
+	if providerID < 0 || providerID >= len(b.cfg.PubsubProviders) {
+		b.errs = append(b.errs, fmt.Errorf("pubsub topic %q: unknown provider id %d", name, providerID))
+		return b
+	}
+	if _, dup := b.cfg.PubsubTopics[name]; dup {
+		b.errs = append(b.errs, fmt.Errorf("pubsub topic %q: defined more than once", name))
+		return b
+	}
+
+	topic := &PubsubTopic{
+		EncoreName:    name,
+		ProviderID:    providerID,
+		ProviderName:  name,
+		Subscriptions: make(map[string]*PubsubSubscription, len(subscriptions)),
+	}
+	for _, sub := range subscriptions {
+		topic.Subscriptions[sub] = &PubsubSubscription{
+			ID:           sub,
+			EncoreName:   sub,
+			ProviderName: sub,
+			Topic:        name,
+		}
+	}
+	if b.cfg.PubsubTopics == nil {
+		b.cfg.PubsubTopics = make(map[string]*PubsubTopic)
+	}
+	b.cfg.PubsubTopics[name] = topic
+	return b
+}
+
+// AddGateway adds a gateway.
+func (b *RuntimeBuilder) AddGateway(gw Gateway) *RuntimeBuilder {
+	b.cfg.Gateways = append(b.cfg.Gateways, gw)
+	return b
+}
+
+// WithHostedServices adds services to the ones hosted in the container.
+func (b *RuntimeBuilder) WithHostedServices(services ...string) *RuntimeBuilder {
+	b.cfg.HostedServices = append(b.cfg.HostedServices, services...)
+	return b
+}
+
+// Err reports the invalid references made while building, joined
+// together using [errors.Join]. Unlike [RuntimeBuilder.Build] it
+// doesn't validate the config as a whole.
+func (b *RuntimeBuilder) Err() error {
+	return errors.Join(b.errs...)
+}
+
+// Build returns the runtime config, after checking it using [Runtime.Validate].
+// It reports every problem found, including invalid references made while
+// building, joined together using [errors.Join].
+//
+// The returned config doesn't share memory with the builder,
+// so the builder can be used to build further configs.
+func (b *RuntimeBuilder) Build() (*Runtime, error) {
+	cfg := b.cfg.Clone()
+	if err := errors.Join(append(b.errs, cfg.validate(validateOptions{})...)...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
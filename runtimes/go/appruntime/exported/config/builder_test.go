@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuntimeBuilder(t *testing.T) {
+	b := NewRuntimeBuilder("app").
+		WithAPIBaseURL("https://api.example.com").
+		WithEnv("prod", EnvProduction, CloudAWS).
+		AddSQLServer(SQLServer{Host: "db.example.com"}).
+		AddSQLDatabase(0, "users", "encore", "secret").
+		AddRedisServer(RedisServer{Host: "cache.example.com"}).
+		AddRedisDatabase(0, "sessions", 1).
+		AddPubsubProvider(PubsubProvider{AWS: &AWSPubsubProvider{}}).
+		AddPubsubTopic(0, "signups", "send-welcome")
+	cfg, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if db, ok := cfg.SQLDatabase("users"); !ok || db.ServerID != 0 || db.Password != "secret" {
+		t.Errorf("SQLDatabase(users) = %+v, %v", db, ok)
+	}
+	if subs := cfg.Subscriptions("signups"); len(subs) != 1 || subs[0].Topic != "signups" {
+		t.Errorf("Subscriptions(signups) = %v", subs)
+	}
+
+	// Building again doesn't share memory with the first config.
+	again, err := b.AddSQLDatabase(0, "orders", "encore", "secret").Build()
+	if err != nil {
+		t.Fatalf("second Build: %v", err)
+	}
+	if len(cfg.SQLDatabases) != 1 || len(again.SQLDatabases) != 2 {
+		t.Errorf("got %d and %d databases, want 1 and 2", len(cfg.SQLDatabases), len(again.SQLDatabases))
+	}
+}
+
+func TestRuntimeBuilderErrors(t *testing.T) {
+	_, err := NewRuntimeBuilder("app").
+		WithAPIBaseURL("https://api.example.com").
+		WithEnv("prod", "prod", CloudAWS).
+		AddSQLDatabase(0, "users", "encore", "secret").
+		AddPubsubProvider(PubsubProvider{NSQ: &NSQProvider{Host: "localhost:4150"}}).
+		AddPubsubTopic(1, "signups").
+		AddPubsubTopic(0, "orders").
+		AddPubsubTopic(0, "orders").
+		Build()
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	for _, want := range []string{
+		`sql database "users": unknown server id 0`,
+		`pubsub topic "signups": unknown provider id 1`,
+		`pubsub topic "orders": defined more than once`,
+		`env type "prod": unknown value`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	if _, err := NewRuntimeBuilder("app").Build(); err == nil || !strings.Contains(err.Error(), "missing api base url") {
+		t.Errorf("missing api base url: got %v", err)
+	}
+}
+
+func TestEditRuntime(t *testing.T) {
+	cfg := &Runtime{
+		PubsubProviders: []*PubsubProvider{{NSQ: &NSQProvider{Host: "localhost:4150"}}},
+	}
+	b := EditRuntime(cfg).
+		AddPubsubProvider(PubsubProvider{GCP: &GCPPubsubProvider{}}).
+		AddPubsubTopic(1, "signups", "send-welcome")
+	if err := b.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+	if topic, ok := cfg.PubsubTopics["signups"]; !ok || topic.ProviderID != 1 {
+		t.Errorf("PubsubTopics[signups] = %+v, %v", topic, ok)
+	}
+
+	if err := b.AddPubsubTopic(2, "orders").Err(); err == nil || !strings.Contains(err.Error(), "unknown provider id 2") {
+		t.Errorf("unknown provider: got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"hash/fnv"
+	"reflect"
+	"slices"
+)
+
+// CanonicalJSON returns a stable JSON encoding of the runtime config,
+// with secrets redacted, that is suitable for diffing configs across
+// deployments.
+//
+// Slices whose order carries no meaning are sorted by name or ID, and
+// references to servers and pubsub providers are renumbered to match
+// the sorted order, so two configs whose only difference is the order
+// of such slices produce identical output. The order of auth keys is
+// preserved since the first key is the one used for signing.
+func (r *Runtime) CanonicalJSON() ([]byte, error) {
+	cfg := r.Redacted()
+	cfg.canonicalize()
+
+	// encoding/json sorts map keys, so maps need no special handling.
+	return json.MarshalIndent(cfg, "", "  ")
+}
+
+// Hash returns a hash of the runtime config that changes whenever
+// the config does, such as to decide whether to reload it.
+//
+// Like [Runtime.Equal], the order of slices whose order carries no
+// meaning doesn't affect the hash. Unlike [Runtime.CanonicalJSON],
+// secrets are included, so that rotating a secret changes the hash.
+// The hash is not cryptographic and must not be published, since
+// it could be used to confirm a guessed secret.
+func (r *Runtime) Hash() uint64 {
+	cfg := r.Clone()
+	cfg.canonicalize()
+	cfg.DeployedAt = cfg.DeployedAt.UTC()
+	nilEmpty(reflect.ValueOf(cfg).Elem())
+
+	// Encoding a Runtime only fails on invalid raw JSON in Extra,
+	// which can't come from a parsed config.
+	h := fnv.New64a()
+	_ = json.NewEncoder(h).Encode(cfg)
+	return h.Sum64()
+}
+
+// canonicalize sorts, in place, the slices of r whose order carries
+// no meaning, renumbering references to servers and pubsub providers
+// to match.
+func (r *Runtime) canonicalize() {
+	// Sort the servers and providers first, since the
+	// databases and topics refer to them by index.
+	sqlIDs := sortIndexed(r.SQLServers, compareJSON[*SQLServer])
+	for _, db := range r.SQLDatabases {
+		db.ServerID = remapIndex(sqlIDs, db.ServerID)
+	}
+	redisIDs := sortIndexed(r.RedisServers, compareJSON[*RedisServer])
+	for _, db := range r.RedisDatabases {
+		db.ServerID = remapIndex(redisIDs, db.ServerID)
+	}
+	providerIDs := sortIndexed(r.PubsubProviders, compareJSON[*PubsubProvider])
+	for _, topic := range r.PubsubTopics {
+		if topic != nil {
+			topic.ProviderID = remapIndex(providerIDs, topic.ProviderID)
+		}
+	}
+
+	slices.SortStableFunc(r.SQLDatabases, func(a, b *SQLDatabase) int {
+		return cmp.Compare(a.EncoreName, b.EncoreName)
+	})
+	slices.SortStableFunc(r.RedisDatabases, func(a, b *RedisDatabase) int {
+		return cmp.Compare(a.EncoreName, b.EncoreName)
+	})
+	slices.SortStableFunc(r.Gateways, func(a, b Gateway) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	slices.SortStableFunc(r.ServiceAuth, func(a, b ServiceAuth) int {
+		return cmp.Compare(a.Method, b.Method)
+	})
+	slices.Sort(r.HostedServices)
+	slices.Sort(r.TrustedProxies)
+	slices.Sort(r.DynamicExperiments)
+	if r.CORS != nil {
+		slices.Sort(r.CORS.AllowOriginsWithCredentials)
+		slices.Sort(r.CORS.AllowOriginsWithoutCredentials)
+		slices.Sort(r.CORS.ExtraAllowedHeaders)
+		slices.Sort(r.CORS.ExtraExposedHeaders)
+		slices.Sort(r.CORS.AllowedMethods)
+	}
+}
+
+// sortIndexed sorts s using compare and returns, for each original
+// index, the index of the same element after sorting.
+func sortIndexed[T any](s []T, compare func(a, b T) int) []int {
+	order := make([]int, len(s))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		return compare(s[i], s[j])
+	})
+
+	sorted := make([]T, len(s))
+	newIndex := make([]int, len(s))
+	for newIdx, oldIdx := range order {
+		sorted[newIdx] = s[oldIdx]
+		newIndex[oldIdx] = newIdx
+	}
+	copy(s, sorted)
+	return newIndex
+}
+
+// remapIndex returns the new index for idx given the mapping returned
+// by sortIndexed. Out of range indices are returned unchanged.
+func remapIndex(newIndex []int, idx int) int {
+	if idx < 0 || idx >= len(newIndex) {
+		return idx
+	}
+	return newIndex[idx]
+}
+
+// compareJSON compares a and b by their JSON encoding, for types
+// without a natural sort key.
+func compareJSON[T any](a, b T) int {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return cmp.Compare(string(ja), string(jb))
+}
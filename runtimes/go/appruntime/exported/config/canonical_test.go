@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	a, err := twoOfEverything(false).CanonicalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := twoOfEverything(true).CanonicalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("canonical JSON differs:\n%s\n\n%s", a, b)
+	}
+
+	for _, secret := range secrets {
+		if strings.Contains(string(a), secret) {
+			t.Errorf("canonical JSON contains secret %q", secret)
+		}
+	}
+
+	// The receiver is left untouched.
+	cfg := twoOfEverything(true)
+	if _, err := cfg.CanonicalJSON(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SQLServers[0].Host != "replica.example.com:5432" {
+		t.Errorf("CanonicalJSON modified the receiver")
+	}
+}
+
+// twoOfEverything returns a config with two of everything, in the
+// given order.
+func twoOfEverything(reversed bool) *Runtime {
+	cfg := fullRuntime()
+	cfg.SQLServers = append(cfg.SQLServers, &SQLServer{Host: "replica.example.com:5432"})
+	cfg.SQLDatabases = append(cfg.SQLDatabases, &SQLDatabase{ServerID: 1, EncoreName: "orders"})
+	cfg.RedisServers = append(cfg.RedisServers, &RedisServer{Host: "cache2.example.com:6379"})
+	cfg.RedisDatabases = append(cfg.RedisDatabases, &RedisDatabase{ServerID: 1, EncoreName: "sessions"})
+	cfg.PubsubProviders = append(cfg.PubsubProviders, &PubsubProvider{NSQ: &NSQProvider{Host: "nsq"}})
+	cfg.PubsubTopics["orders"] = &PubsubTopic{EncoreName: "orders", ProviderID: 1}
+	cfg.Gateways = append(cfg.Gateways, Gateway{Name: "admin-gateway"})
+	cfg.HostedServices = append(cfg.HostedServices, "billing")
+	if !reversed {
+		return cfg
+	}
+
+	slices.Reverse(cfg.SQLServers)
+	for _, db := range cfg.SQLDatabases {
+		db.ServerID = 1 - db.ServerID
+	}
+	slices.Reverse(cfg.SQLDatabases)
+	slices.Reverse(cfg.RedisServers)
+	for _, db := range cfg.RedisDatabases {
+		db.ServerID = 1 - db.ServerID
+	}
+	slices.Reverse(cfg.RedisDatabases)
+	slices.Reverse(cfg.PubsubProviders)
+	for _, topic := range cfg.PubsubTopics {
+		topic.ProviderID = 1 - topic.ProviderID
+	}
+	slices.Reverse(cfg.Gateways)
+	slices.Reverse(cfg.HostedServices)
+	return cfg
+}
+
+func TestHash(t *testing.T) {
+	a, b := twoOfEverything(false), twoOfEverything(true)
+	if a.Hash() != b.Hash() {
+		t.Errorf("reordered configs hash differently")
+	}
+	if a.Hash() != a.Hash() {
+		t.Errorf("hash is not deterministic")
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Runtime)
+	}{
+		{"env name", func(r *Runtime) { r.EnvName = "production" }},
+		{"sql host", func(r *Runtime) { r.SQLServers[0].Host = "other.example.com:5432" }},
+		{"rotated password", func(r *Runtime) { r.SQLDatabases[0].Password = "rotated" }},
+		{"added service", func(r *Runtime) { r.HostedServices = append(r.HostedServices, "extra") }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := twoOfEverything(false)
+			test.modify(cfg)
+			if cfg.Hash() == a.Hash() {
+				t.Errorf("hash unchanged")
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errChecksumMismatch is reported when a config doesn't match its checksum.
+var errChecksumMismatch = errors.New("encore runtime config does not match its checksum")
+
+// ChecksumRuntime returns the checksum of the encoded runtime config,
+// as verified by [ParseRuntimeVerified]: the hex-encoded SHA-256 hash
+// of the config string exactly as given, before it is decoded.
+func ChecksumRuntime(config string) string {
+	sum := sha256.Sum256([]byte(config))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseRuntimeVerified is like [ParseRuntimeErr] but first checks that
+// the config matches checksum, as computed by [ChecksumRuntime], to detect
+// configs corrupted in transit. The checksum is case-insensitive.
+//
+// If checksum is empty, the config is not verified.
+func ParseRuntimeVerified(config, checksum, deployID string) (*Runtime, error) {
+	if checksum = strings.TrimSpace(checksum); checksum != "" {
+		if got := ChecksumRuntime(config); !strings.EqualFold(got, checksum) {
+			return nil, &ParseError{Stage: StageDecode, Err: fmt.Errorf("%w (got %s, want %s)", errChecksumMismatch, got, checksum)}
+		}
+	}
+	return ParseRuntimeErr(config, deployID)
+}
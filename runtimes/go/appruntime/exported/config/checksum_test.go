@@ -0,0 +1,53 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChecksumRuntime(t *testing.T) {
+	// The SHA-256 of the empty string.
+	const empty = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if got := ChecksumRuntime(""); got != empty {
+		t.Errorf("got %s, want %s", got, empty)
+	}
+}
+
+func TestParseRuntimeVerified(t *testing.T) {
+	config, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum := ChecksumRuntime(config)
+
+	tests := []struct {
+		name     string
+		config   string
+		checksum string
+		wantErr  error
+	}{
+		{name: "match", config: config, checksum: checksum},
+		{name: "match_uppercase", config: config, checksum: strings.ToUpper(checksum)},
+		{name: "empty_checksum", config: config, checksum: ""},
+		{name: "mismatch", config: config, checksum: ChecksumRuntime("other"), wantErr: errChecksumMismatch},
+		{name: "corrupted", config: config[:len(config)-4], checksum: checksum, wantErr: errChecksumMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseRuntimeVerified(tt.config, tt.checksum, "override")
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if cfg.DeployID != "override" {
+					t.Errorf("got deploy id %q, want %q", cfg.DeployID, "override")
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// chunkEncoding describes the base64 variant a chunk of a config uses.
+type chunkEncoding struct {
+	// alphabet is "standard" or "url", or "" if the chunk
+	// contains none of the characters that tell them apart.
+	alphabet string
+	padded   bool // whether the chunk ends in '=' padding
+}
+
+// chunkEncodingOf reports the base64 variant of chunk. It reports
+// ok=false if chunk isn't base64 in a single variant, in which case
+// the chunk is broken by itself rather than mismatched with the others.
+func chunkEncodingOf(chunk string) (ce chunkEncoding, ok bool) {
+	s := cleanConfig(chunk)
+	data := strings.TrimRight(s, "=")
+	ce.padded = len(data) < len(s)
+	for _, c := range data {
+		var alphabet string
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			continue
+		case c == '+' || c == '/':
+			alphabet = "standard"
+		case c == '-' || c == '_':
+			alphabet = "url"
+		default:
+			return ce, false
+		}
+		if ce.alphabet != "" && ce.alphabet != alphabet {
+			return ce, false
+		}
+		ce.alphabet = alphabet
+	}
+	return ce, true
+}
+
+// mixedChunkEncodings reports an error naming the first chunk whose
+// base64 variant doesn't match the chunks before it, such as when the
+// chunks were produced by different tools. Since the chunks are joined
+// before decoding, only the last chunk may be padded. It returns nil
+// if the chunks are consistent, or aren't all base64.
+func mixedChunkEncodings(chunks []string) error {
+	encs := make([]chunkEncoding, len(chunks))
+	for i, chunk := range chunks {
+		ce, ok := chunkEncodingOf(chunk)
+		if !ok {
+			return nil
+		}
+		encs[i] = ce
+	}
+
+	first := -1 // the first chunk with a known alphabet
+	for i, ce := range encs {
+		if ce.padded && i < len(chunks)-1 {
+			return fmt.Errorf("encore runtime config chunk %d is padded, but only the last chunk may be; "+
+				"were the chunks base64-encoded separately?", i)
+		}
+		if ce.alphabet == "" {
+			continue
+		}
+		if first < 0 {
+			first = i
+		} else if ce.alphabet != encs[first].alphabet {
+			return fmt.Errorf("encore runtime config chunk %d uses the %s base64 alphabet, but chunk %d uses the %s one",
+				i, ce.alphabet, first, encs[first].alphabet)
+		}
+	}
+	return nil
+}
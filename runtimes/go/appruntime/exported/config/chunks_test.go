@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseRuntimeChunksMixedEncodings(t *testing.T) {
+	// The env name encodes to base64 characters that
+	// differ between the alphabets, whatever its offset.
+	cfg := fullRuntime()
+	cfg.EnvName = "??????>>>>>>"
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := base64.StdEncoding.EncodeToString(data)
+	url := base64.RawURLEncoding.EncodeToString(data)
+	if !strings.ContainsAny(std, "+/") || !strings.ContainsAny(url, "-_") {
+		t.Fatal("test config does not tell the base64 alphabets apart")
+	}
+
+	// splitAt returns the index of the first character that's
+	// specific to an alphabet, so that each chunk has one.
+	splitAt := func(s string) int {
+		return strings.IndexAny(s, "+/-_") + 1
+	}
+	half := splitAt(std)
+
+	tests := []struct {
+		name    string
+		chunks  []string
+		wantErr string
+	}{
+		{
+			name:    "alphabets",
+			chunks:  []string{std[:half], url[half:]},
+			wantErr: "chunk 1 uses the url base64 alphabet, but chunk 0 uses the standard one",
+		},
+		{
+			name: "padding",
+			chunks: []string{
+				base64.StdEncoding.EncodeToString(data[:10]),
+				base64.StdEncoding.EncodeToString(data[10:]),
+			},
+			wantErr: "chunk 0 is padded, but only the last chunk may be",
+		},
+		{
+			name:   "consistent",
+			chunks: []string{url[:splitAt(url)], url[splitAt(url):]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRuntimeChunks(tt.chunks, "")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Stage != StageDecode {
+				t.Fatalf("got %v, want a ParseError at stage %q", err, StageDecode)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+
+	"go.encore.dev/platform-sdk/pkg/auth"
+)
+
+// Clone returns a deep copy of the runtime config.
+// Modifying the clone, including any nested values, does not affect r.
+// The clone is never frozen, even if r is.
+func (r *Runtime) Clone() *Runtime {
+	if r == nil {
+		return nil
+	}
+
+	c := *r
+	c.frozen = false
+	c.AuthKeys = cloneSlice(r.AuthKeys, EncoreAuthKey.clone)
+	c.CORS = r.CORS.clone()
+	c.EncoreCloudAPI = r.EncoreCloudAPI.clone()
+	c.SQLDatabases = cloneSlice(r.SQLDatabases, clonePtr[SQLDatabase])
+	c.SQLServers = cloneSlice(r.SQLServers, clonePtr[SQLServer])
+	c.PubsubProviders = cloneSlice(r.PubsubProviders, (*PubsubProvider).clone)
+	c.PubsubTopics = cloneMap(r.PubsubTopics, (*PubsubTopic).clone)
+	c.RedisServers = cloneSlice(r.RedisServers, clonePtr[RedisServer])
+	c.RedisDatabases = cloneSlice(r.RedisDatabases, clonePtr[RedisDatabase])
+	c.Metrics = r.Metrics.clone()
+	c.Gateways = cloneSlice(r.Gateways, Gateway.clone)
+	c.HostedServices = slices.Clone(r.HostedServices)
+	c.ServiceDiscovery = maps.Clone(r.ServiceDiscovery)
+	c.ServiceAuth = slices.Clone(r.ServiceAuth)
+	c.TrustedProxies = slices.Clone(r.TrustedProxies)
+	c.GracefulShutdown = r.GracefulShutdown.clone()
+	c.Requests = r.Requests.clone()
+	c.Tracing = r.Tracing.clone()
+	c.DynamicExperiments = slices.Clone(r.DynamicExperiments)
+	c.Flags = maps.Clone(r.Flags)
+	c.Extra = cloneMap(r.Extra, slices.Clone[json.RawMessage])
+	return &c
+}
+
+func (eak EncoreAuthKey) clone() EncoreAuthKey {
+	eak.Data = slices.Clone(eak.Data)
+	return eak
+}
+
+func (g Gateway) clone() Gateway {
+	g.Services = slices.Clone(g.Services)
+	g.RateLimiting = clonePtr(g.RateLimiting)
+	return g
+}
+
+func (c *CORS) clone() *CORS {
+	if c == nil {
+		return nil
+	}
+	cc := *c
+	cc.AllowOriginsWithCredentials = slices.Clone(c.AllowOriginsWithCredentials)
+	cc.AllowOriginsWithoutCredentials = slices.Clone(c.AllowOriginsWithoutCredentials)
+	cc.ExtraAllowedHeaders = slices.Clone(c.ExtraAllowedHeaders)
+	cc.ExtraExposedHeaders = slices.Clone(c.ExtraExposedHeaders)
+	cc.AllowedMethods = slices.Clone(c.AllowedMethods)
+	return &cc
+}
+
+func (api *EncoreCloudAPI) clone() *EncoreCloudAPI {
+	if api == nil {
+		return nil
+	}
+	c := *api
+	c.AuthKeys = cloneSlice(api.AuthKeys, func(k auth.Key) auth.Key {
+		k.Data = slices.Clone(k.Data)
+		return k
+	})
+	return &c
+}
+
+func (p *PubsubProvider) clone() *PubsubProvider {
+	if p == nil {
+		return nil
+	}
+	return &PubsubProvider{
+		NSQ:         clonePtr(p.NSQ),
+		GCP:         clonePtr(p.GCP),
+		AWS:         clonePtr(p.AWS),
+		Azure:       clonePtr(p.Azure),
+		EncoreCloud: clonePtr(p.EncoreCloud),
+	}
+}
+
+func (t *PubsubTopic) clone() *PubsubTopic {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	if t.Limiter != nil {
+		c.Limiter = &Limiter{TokenBucket: clonePtr(t.Limiter.TokenBucket)}
+	}
+	c.Subscriptions = cloneMap(t.Subscriptions, func(s *PubsubSubscription) *PubsubSubscription {
+		if s == nil {
+			return nil
+		}
+		sc := *s
+		sc.GCP = clonePtr(s.GCP)
+		return &sc
+	})
+	c.GCP = clonePtr(t.GCP)
+	return &c
+}
+
+func (m *Metrics) clone() *Metrics {
+	if m == nil {
+		return nil
+	}
+	cloneGCP := func(p *GCPCloudMonitoringProvider) *GCPCloudMonitoringProvider {
+		if p == nil {
+			return nil
+		}
+		c := *p
+		c.MonitoredResourceLabels = maps.Clone(p.MonitoredResourceLabels)
+		c.MetricNames = maps.Clone(p.MetricNames)
+		return &c
+	}
+	return &Metrics{
+		CollectionInterval: m.CollectionInterval,
+		EncoreCloud:        cloneGCP(m.EncoreCloud),
+		CloudMonitoring:    cloneGCP(m.CloudMonitoring),
+		CloudWatch:         clonePtr(m.CloudWatch),
+		LogsBased:          clonePtr(m.LogsBased),
+		Prometheus:         clonePtr(m.Prometheus),
+		Datadog:            clonePtr(m.Datadog),
+	}
+}
+
+func (g *GracefulShutdownTimings) clone() *GracefulShutdownTimings {
+	if g == nil {
+		return nil
+	}
+	return &GracefulShutdownTimings{
+		Total:         clonePtr(g.Total),
+		ShutdownHooks: clonePtr(g.ShutdownHooks),
+		Handlers:      clonePtr(g.Handlers),
+	}
+}
+
+func (rc *RequestConfig) clone() *RequestConfig {
+	if rc == nil {
+		return nil
+	}
+	return &RequestConfig{Timeout: clonePtr(rc.Timeout)}
+}
+
+func (t *Tracing) clone() *Tracing {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.SampleRate = clonePtr(t.SampleRate)
+	return &c
+}
+
+// clonePtr returns a shallow copy of the value p points to.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+// cloneSlice returns a copy of s with each element copied using clone.
+func cloneSlice[T any](s []T, clone func(T) T) []T {
+	if s == nil {
+		return nil
+	}
+	c := make([]T, len(s))
+	for i, v := range s {
+		c[i] = clone(v)
+	}
+	return c
+}
+
+// cloneMap returns a copy of m with each value copied using clone.
+func cloneMap[K comparable, V any](m map[K]V, clone func(V) V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = clone(v)
+	}
+	return c
+}
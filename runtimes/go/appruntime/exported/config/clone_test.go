@@ -0,0 +1,34 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	orig := fullRuntime()
+	clone := orig.Clone()
+	if !reflect.DeepEqual(clone, orig) {
+		t.Fatalf("clone differs from original:\ngot  %+v\nwant %+v", clone, orig)
+	}
+
+	clone.SQLDatabases[0].Password = "changed"
+	clone.SQLServers[0].Host = "changed"
+	clone.RedisServers[0].Password = "changed"
+	clone.AuthKeys[0].Data[0] = 'X'
+	clone.PubsubTopics["signups"].Subscriptions["send-welcome"].ProviderName = "changed"
+	clone.Gateways[0].Host = "changed"
+	clone.CORS.AllowOriginsWithCredentials[0] = "changed"
+	*clone.GracefulShutdown.Total = 0
+
+	if !reflect.DeepEqual(orig, fullRuntime()) {
+		t.Errorf("modifying the clone affected the original:\n%+v", orig)
+	}
+	if got := orig.SQLDatabases[0].Password; got != "sql-password-secret" {
+		t.Errorf("original sql password: got %q", got)
+	}
+
+	if (*Runtime)(nil).Clone() != nil {
+		t.Error("cloning a nil config should return nil")
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// encoreCloudDomain is the domain apps running in Encore Cloud
+// are served from, such as "staging-myapp-ab12.encr.app".
+const encoreCloudDomain = "encr.app"
+
+// checkCloudURL checks that the host of the API base URL is one that
+// can serve the app in its cloud: apps in Encore Cloud must be served
+// from the managed domain, and apps deployed to any other cloud
+// must not be served from localhost.
+//
+// A mismatch usually means the config was generated for the wrong
+// environment. Configs for local development are not checked.
+func (r *Runtime) checkCloudURL() error {
+	if r.EnvCloud == CloudLocal || r.EnvCloud == "" {
+		return nil
+	}
+	u, err := url.Parse(r.APIBaseURL)
+	if err != nil {
+		return fmt.Errorf("api base url %q: %w", r.APIBaseURL, err)
+	}
+	host := strings.ToLower(u.Hostname())
+
+	if isLoopbackHost(host) {
+		return fmt.Errorf("api base url %q: host %q is not reachable in %s environment %q running in the %s cloud",
+			r.APIBaseURL, host, r.EnvType, r.EnvName, r.EnvCloud)
+	}
+	if r.EnvCloud == CloudEncore && !strings.HasSuffix(host, "."+encoreCloudDomain) {
+		return fmt.Errorf("api base url %q: host %q is not under %q, as expected in the %s cloud",
+			r.APIBaseURL, host, encoreCloudDomain, r.EnvCloud)
+	}
+	return nil
+}
+
+// isLoopbackHost reports whether host refers to the local machine.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithCloudURLCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		envType    string
+		envCloud   string
+		apiBaseURL string
+		wantErr    string
+	}{
+		{"production_localhost", EnvProduction, CloudAWS, "http://localhost:4000", `host "localhost" is not reachable in production environment`},
+		{"production_loopback_ip", EnvProduction, CloudGCP, "http://127.0.0.1:8080", `host "127.0.0.1" is not reachable`},
+		{"production_valid", EnvProduction, CloudAWS, "https://api.example.com", ""},
+		{"encore_managed", EnvProduction, CloudEncore, "https://prod-app-ab12.encr.app", ""},
+		{"encore_custom_domain", EnvProduction, CloudEncore, "https://api.example.com", `is not under "encr.app"`},
+		{"local_localhost", EnvDevelopment, CloudLocal, "http://localhost:4000", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := encodeJSON(t, map[string]any{
+				"env_name":     "env",
+				"env_type":     tt.envType,
+				"env_cloud":    tt.envCloud,
+				"api_base_url": tt.apiBaseURL,
+			})
+			_, err := ParseRuntimeOptions(config, WithCloudURLCheck())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+
+			// Without the option the config is accepted.
+			if _, err := ParseRuntimeOptions(config); err != nil {
+				t.Errorf("without check: unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -36,6 +37,10 @@ type Static struct {
 }
 
 type Runtime struct {
+	// SchemaVersion is the version of the runtime config schema.
+	// Configs without a version are treated as version 0.
+	SchemaVersion int `json:"schema_version,omitempty"`
+
 	AppID          string          `json:"app_id"`
 	AppSlug        string          `json:"app_slug"`
 	APIBaseURL     string          `json:"api_base_url"`
@@ -61,6 +66,13 @@ type Runtime struct {
 	HostedServices   []string                `json:"hosted_services,omitempty"`   // List of services to be hosted within this container (zero length means all services, unless there's a gateway running)
 	ServiceDiscovery map[string]Service      `json:"service_discovery,omitempty"` // ServiceDiscovery lists where all the services are being hosted if not in this container
 
+	// TrustedProxies are the CIDR ranges, such as "10.0.0.0/8", of the
+	// proxies and load balancers in front of the app. The runtime only
+	// validates them; it is up to code that inspects forwarding headers
+	// to decide whether to trust them based on these ranges.
+	// Use [Runtime.TrustedProxyNets] to read them.
+	TrustedProxies []string `json:"trusted_proxies,omitempty"`
+
 	// ServiceAuth defines which authentication method can be used
 	// when talking to this runtime for internal service-to-service
 	// calls.
@@ -73,20 +85,44 @@ type Runtime struct {
 	// If zero, it shuts down immediately.
 	//
 	// Deprecated: Use GracefulShutdown.Total instead.
-	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+	ShutdownTimeout Duration `json:"shutdown_timeout" encore:"deprecated"`
 
 	// GracefulShutdown defines the timings for the graceful shutdown process.
 	GracefulShutdown *GracefulShutdownTimings `json:"graceful_shutdown,omitempty"`
 
+	// Requests configures how incoming requests are handled.
+	Requests *RequestConfig `json:"requests,omitempty"`
+
+	// Tracing describes where to export traces using OpenTelemetry.
+	// It is configuration only; see [Tracing].
+	// Use [Runtime.OTelTracingEnabled] to check whether it is enabled.
+	Tracing *Tracing `json:"tracing,omitempty"`
+
 	// DynamicExperiments is a list of experiments that are enabled for this app
 	// which impact runtime behaviour, but which were not enabled at compile time.
 	//
 	// Experiments which impact compilation should be handled by the compiler
 	// and added to the static config.
 	DynamicExperiments []string `json:"dynamic_experiments,omitempty"`
+
+	// Flags are experimental feature flags, keyed by name.
+	// Use the typed getters like [Runtime.FlagBool] to read them.
+	Flags map[string]string `json:"flags,omitempty"`
+
+	// Extra holds the top-level fields of the config that this version
+	// of the runtime doesn't know about, keyed by name. They are kept
+	// so that tooling can re-encode newer configs without losing them.
+	// It's only populated when parsing with [WithExtraFields].
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// frozen is set by Freeze to make the mutation helpers fail.
+	frozen bool
 }
 
 // GracefulShutdownTimings defines the timings for the graceful shutdown process.
+//
+// The timings can be given either as a number of nanoseconds
+// or as a duration string like "30s"; see [Duration].
 type GracefulShutdownTimings struct {
 	// Total is how long we allow the total shutdown to take
 	// before we simply kill the process using [os.Exit]
@@ -96,7 +132,7 @@ type GracefulShutdownTimings struct {
 	//
 	// If [Runtime.ShutdownTimeout] is also not set, it will default to
 	// 500ms.
-	Total *time.Duration `json:"total,omitempty"`
+	Total *Duration `json:"total,omitempty"`
 
 	// ShutdownHooks is how long before [Total] runs out that we cancel
 	// the context that is passed to the shutdown hooks.
@@ -104,7 +140,7 @@ type GracefulShutdownTimings struct {
 	// If not set, it will default to 1 second.
 	//
 	// It is expected that ShutdownHooks is a larger value than Handlers.
-	ShutdownHooks *time.Duration `json:"shutdown_hooks,omitempty"`
+	ShutdownHooks *Duration `json:"shutdown_hooks,omitempty"`
 
 	// Handlers is how long before [Total] runs out that we cancel
 	// the context that is passed to API and PubSub Subscription handlers.
@@ -114,7 +150,33 @@ type GracefulShutdownTimings struct {
 	// For example, if [Total] is 10 seconds and [Handlers] is 2 seconds,
 	// then we will cancel the context passed to handlers 8 seconds after
 	// a graceful shutdown is initiated.
-	Handlers *time.Duration `json:"handlers,omitempty"`
+	Handlers *Duration `json:"handlers,omitempty"`
+}
+
+// RequestConfig configures how incoming requests are handled.
+type RequestConfig struct {
+	// Timeout is the maximum duration of a request handled by the
+	// API server, after which its context is canceled. This includes
+	// streaming requests. If not set, requests have no deadline.
+	//
+	// Use [Runtime.RequestTimeout] to read it.
+	Timeout *Duration `json:"timeout,omitempty"`
+}
+
+// Tracing describes where to export traces using OpenTelemetry.
+// The Go runtime does not export traces this way itself; the settings
+// are carried for an exporter set up alongside the app.
+type Tracing struct {
+	// Provider is the name of the tracing provider, such as "otlp".
+	Provider string `json:"provider"`
+
+	// Endpoint is the URL of the collector to export traces to,
+	// such as "https://otel-collector:4318".
+	Endpoint string `json:"endpoint"`
+
+	// SampleRate is the fraction of traces to export, between 0 and 1.
+	// If nil, all traces are exported.
+	SampleRate *float64 `json:"sample_rate,omitempty"`
 }
 
 // Gateway defines the configuration of a gateway which should be served
@@ -122,8 +184,17 @@ type GracefulShutdownTimings struct {
 type Gateway struct {
 	// Name is the name of the gateway
 	Name string `json:"name"`
-	// Host is the hostname of the gateway
+	// Host is the hostname of the gateway.
+	// It may start with "*." to match any subdomain.
 	Host string `json:"host"`
+	// Services are the services the gateway routes requests to.
+	// If empty, the gateway routes to all services.
+	Services []string `json:"services,omitempty"`
+	// RateLimiting describes the rate limit to apply to requests the
+	// gateway accepts. The Go runtime does not enforce it; it is carried
+	// for the infrastructure in front of the gateway to apply.
+	// Use [Gateway.RateLimit] to read it.
+	RateLimiting *GatewayRateLimit `json:"rate_limit,omitempty"`
 }
 
 // Service defines the service discovery configuration for a service
@@ -188,6 +259,10 @@ type CORS struct {
 	// As a special case, if the list contains "*" all headers are allowed.
 	ExtraExposedHeaders []string `json:"raw_exposed_headers,omitempty"`
 
+	// AllowedMethods specifies the HTTP methods to allow.
+	// If nil it defaults to all the standard HTTP methods.
+	AllowedMethods []string `json:"allowed_methods,omitempty"`
+
 	// AllowAccessWhenOnPrivateNetwork, if true, allows requests to Encore apps running
 	// on private networks from websites.
 	//
@@ -207,6 +282,9 @@ func (ci CommitInfo) AsRevisionString() string {
 	return ci.Revision
 }
 
+// Copy returns a copy of the runtime config.
+//
+// Deprecated: Copy shares most nested values with r. Use [Runtime.Clone] instead.
 func (r *Runtime) Copy() *Runtime {
 	cfg := *r
 	cfg.AuthKeys = make([]EncoreAuthKey, len(r.AuthKeys))
@@ -288,6 +366,11 @@ type PubsubSubscription struct {
 	ProviderName string `json:"provider_name"` // the name for the pubsub subscription as defined by the provider
 	PushOnly     bool   `json:"push_only"`     // if true the application will not actively subscribe to the pub, but instead will rely on HTTP push messages
 
+	// Topic is the Encore name of the topic the subscription is for.
+	// It's optional, since subscriptions are listed under their topic,
+	// but if set it must match that topic.
+	Topic string `json:"topic,omitempty"`
+
 	// GCP contains GCP-specific configuration.
 	// It is set if the subscription exists in GCP.
 	GCP *PubsubSubscriptionGCPData `json:"gcp,omitempty"`
@@ -345,6 +428,10 @@ type SQLDatabase struct {
 	// MaxConnections is the maximum number of open connections to use
 	// for this database. If zero it defaults to 30.
 	MaxConnections int `json:"max_connections"`
+
+	// ConnMaxLifetime is the maximum amount of time a connection may
+	// be reused. If zero it defaults to one hour.
+	ConnMaxLifetime Duration `json:"conn_max_lifetime,omitempty"`
 }
 
 type RedisServer struct {
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"log"
+	"sync"
+)
+
+// Configs holds the runtime config together with the static config,
+// which is only parsed when first needed.
+type Configs struct {
+	// Runtime is the parsed runtime config.
+	Runtime *Runtime
+
+	staticConfig string // the encoded static config
+	staticOnce   sync.Once
+	static       *Static
+	staticErr    error
+}
+
+// parseStatic parses the static config for (*Configs).Static.
+// It's a variable so tests can observe when it's called.
+var parseStatic = ParseStaticErr
+
+// LoadConfigs parses the runtime config, using deployID to override
+// the deploy ID if non-empty, and keeps the static config to be
+// parsed on the first call to [Configs.Static].
+//
+// Like [ParseRuntime], it terminates the process if the runtime config
+// cannot be parsed.
+func LoadConfigs(runtimeStr, staticStr, deployID string) *Configs {
+	return &Configs{
+		Runtime:      ParseRuntime(runtimeStr, deployID),
+		staticConfig: staticStr,
+	}
+}
+
+// Static returns the static config, parsing it on the first call.
+// Subsequent calls return the same *Static.
+//
+// Like [ParseStatic], it terminates the process if the static config
+// cannot be parsed. Use [Configs.StaticErr] to handle the error instead.
+func (c *Configs) Static() *Static {
+	s, err := c.StaticErr()
+	if err != nil {
+		log.Fatalln("encore runtime: fatal error:", err)
+	}
+	return s
+}
+
+// StaticErr is like [Configs.Static] but returns an error
+// instead of terminating the process.
+func (c *Configs) StaticErr() (*Static, error) {
+	c.staticOnce.Do(func() {
+		c.static, c.staticErr = parseStatic(c.staticConfig)
+	})
+	return c.static, c.staticErr
+}
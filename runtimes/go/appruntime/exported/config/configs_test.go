@@ -0,0 +1,55 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestLoadConfigs(t *testing.T) {
+	runtimeStr, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(&Static{EncoreCompiler: "v1.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	staticStr := base64.StdEncoding.EncodeToString(data)
+
+	calls := 0
+	orig := parseStatic
+	parseStatic = func(config string) (*Static, error) {
+		calls++
+		return orig(config)
+	}
+	t.Cleanup(func() { parseStatic = orig })
+
+	cfgs := LoadConfigs(runtimeStr, staticStr, "override")
+	if cfgs.Runtime.DeployID != "override" {
+		t.Errorf("got deploy id %q, want %q", cfgs.Runtime.DeployID, "override")
+	}
+	if calls != 0 {
+		t.Fatalf("static config was parsed %d times before being accessed", calls)
+	}
+
+	s := cfgs.Static()
+	if s.EncoreCompiler != "v1.2.3" {
+		t.Errorf("got compiler %q, want %q", s.EncoreCompiler, "v1.2.3")
+	}
+	if again := cfgs.Static(); again != s || calls != 1 {
+		t.Errorf("got %d parses and a different *Static on the second call, want 1 parse and the same one", calls)
+	}
+}
+
+func TestConfigsStaticErr(t *testing.T) {
+	runtimeStr, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgs := LoadConfigs(runtimeStr, "", "")
+	if _, err := cfgs.StaticErr(); !errors.Is(err, errNoStaticConfig) {
+		t.Errorf("got %v, want %v", err, errNoStaticConfig)
+	}
+}
@@ -0,0 +1,115 @@
+// Package configtest provides helpers for constructing runtime configs in tests.
+package configtest
+
+import (
+	"time"
+
+	"encore.dev/appruntime/exported/config"
+)
+
+// TestOption modifies the runtime config produced by [NewTestRuntime].
+type TestOption func(*config.Runtime)
+
+// NewTestRuntime returns a minimal runtime config that passes
+// (*config.Runtime).Validate, modified by the given options.
+//
+// The config describes a local test environment served at
+// http://localhost:4000 with all services hosted in the container.
+func NewTestRuntime(opts ...TestOption) *config.Runtime {
+	cfg := &config.Runtime{
+		AppID:      "test-app",
+		AppSlug:    "test-app",
+		APIBaseURL: "http://localhost:4000",
+		EnvID:      "test",
+		EnvName:    "test",
+		EnvType:    config.EnvTest,
+		EnvCloud:   config.CloudLocal,
+		DeployID:   "test-deploy",
+		DeployedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithAPIBaseURL sets the API base URL.
+func WithAPIBaseURL(apiBaseURL string) TestOption {
+	return func(cfg *config.Runtime) {
+		cfg.APIBaseURL = apiBaseURL
+	}
+}
+
+// WithEnv sets the environment type and cloud.
+func WithEnv(envType, envCloud string) TestOption {
+	return func(cfg *config.Runtime) {
+		cfg.EnvType = envType
+		cfg.EnvCloud = envCloud
+	}
+}
+
+// WithHostedServices sets the services hosted in the container.
+func WithHostedServices(services ...string) TestOption {
+	return func(cfg *config.Runtime) {
+		cfg.HostedServices = append(cfg.HostedServices, services...)
+	}
+}
+
+// WithSQLDatabase adds a SQL database with the given Encore name.
+// All databases share a single local server, which is added as needed.
+func WithSQLDatabase(name string) TestOption {
+	return func(cfg *config.Runtime) {
+		if len(cfg.SQLServers) == 0 {
+			cfg.SQLServers = append(cfg.SQLServers, &config.SQLServer{Host: "localhost:5432"})
+		}
+		cfg.SQLDatabases = append(cfg.SQLDatabases, &config.SQLDatabase{
+			ServerID:     0,
+			EncoreName:   name,
+			DatabaseName: name,
+			User:         "encore",
+			Password:     "encore",
+		})
+	}
+}
+
+// WithRedisDatabase adds a Redis database with the given Encore name.
+// All databases share a single local server, which is added as needed,
+// and are kept apart by a key prefix.
+func WithRedisDatabase(name string) TestOption {
+	return func(cfg *config.Runtime) {
+		if len(cfg.RedisServers) == 0 {
+			cfg.RedisServers = append(cfg.RedisServers, &config.RedisServer{Host: "localhost:6379"})
+		}
+		cfg.RedisDatabases = append(cfg.RedisDatabases, &config.RedisDatabase{
+			ServerID:   0,
+			EncoreName: name,
+			KeyPrefix:  name + "/",
+		})
+	}
+}
+
+// WithPubsubTopic adds a pubsub topic with the given Encore name
+// and subscriptions. All topics share a single local NSQ provider,
+// which is added as needed.
+//
+// It panics if a topic with the same name has already been added.
+func WithPubsubTopic(name string, subscriptions ...string) TestOption {
+	return func(cfg *config.Runtime) {
+		b := config.EditRuntime(cfg)
+		if len(cfg.PubsubProviders) == 0 {
+			b.AddPubsubProvider(config.PubsubProvider{
+				NSQ: &config.NSQProvider{Host: "localhost:4150"},
+			})
+		}
+		if err := b.AddPubsubTopic(0, name, subscriptions...).Err(); err != nil {
+			panic("configtest: " + err.Error())
+		}
+	}
+}
+
+// WithAuthKey adds a service-to-service auth key with the given id.
+func WithAuthKey(id uint32, data string) TestOption {
+	return func(cfg *config.Runtime) {
+		cfg.AuthKeys = append(cfg.AuthKeys, config.EncoreAuthKey{KeyID: id, Data: []byte(data)})
+	}
+}
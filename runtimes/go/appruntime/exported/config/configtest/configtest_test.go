@@ -0,0 +1,78 @@
+package configtest
+
+import (
+	"testing"
+
+	"encore.dev/appruntime/exported/config"
+)
+
+func TestNewTestRuntime(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []TestOption
+	}{
+		{name: "minimal"},
+		{
+			name: "sql",
+			opts: []TestOption{WithSQLDatabase("users"), WithSQLDatabase("orders")},
+		},
+		{
+			name: "redis",
+			opts: []TestOption{WithRedisDatabase("cache"), WithRedisDatabase("sessions")},
+		},
+		{
+			name: "pubsub",
+			opts: []TestOption{WithPubsubTopic("signups", "welcome-email", "analytics"), WithPubsubTopic("orders")},
+		},
+		{
+			name: "everything",
+			opts: []TestOption{
+				WithAPIBaseURL("https://api.example.com"),
+				WithEnv(config.EnvProduction, config.CloudAWS),
+				WithHostedServices("users", "orders"),
+				WithSQLDatabase("users"),
+				WithRedisDatabase("cache"),
+				WithPubsubTopic("signups", "welcome-email"),
+				WithAuthKey(1, "test-auth-key-secret"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewTestRuntime(tt.opts...)
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewTestRuntime_Options(t *testing.T) {
+	cfg := NewTestRuntime(
+		WithSQLDatabase("users"),
+		WithSQLDatabase("orders"),
+		WithPubsubTopic("signups", "welcome-email"),
+	)
+	if got := len(cfg.SQLServers); got != 1 {
+		t.Errorf("got %d sql servers, want 1", got)
+	}
+	if db, ok := cfg.SQLDatabase("orders"); !ok || db.ServerID != 0 {
+		t.Errorf("SQLDatabase(%q) = %+v, %v", "orders", db, ok)
+	}
+	topic, ok := cfg.PubsubTopics["signups"]
+	if !ok {
+		t.Fatalf("missing pubsub topic %q", "signups")
+	}
+	if _, ok := topic.Subscriptions["welcome-email"]; !ok {
+		t.Errorf("missing subscription %q", "welcome-email")
+	}
+}
+
+func TestWithPubsubTopic_Duplicate(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected a panic for a duplicate topic")
+		}
+	}()
+	NewTestRuntime(WithPubsubTopic("signups"), WithPubsubTopic("signups"))
+}
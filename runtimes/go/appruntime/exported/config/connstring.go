@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SQLConnString returns a postgres:// connection URL for the SQL database
+// with the given Encore name.
+//
+// The TLS mode is the one the runtime connects with; see [SQLServer.SSLMode].
+// Certificates can't be passed in a URL, so callers connecting with TLS
+// must still add them to their TLS config.
+func (r *Runtime) SQLConnString(dbName string) (string, error) {
+	db, ok := r.SQLDatabase(dbName)
+	if !ok {
+		return "", fmt.Errorf("sql database %q: not found", dbName)
+	}
+	srv, ok := r.SQLServer(db.ServerID)
+	if !ok {
+		return "", fmt.Errorf("sql database %q: unknown server id %d", dbName, db.ServerID)
+	}
+
+	u := &url.URL{
+		Scheme: "postgres",
+		Path:   "/" + db.DatabaseName,
+	}
+	if db.Password != "" {
+		u.User = url.UserPassword(db.User, db.Password)
+	} else if db.User != "" {
+		u.User = url.User(db.User)
+	}
+
+	q := url.Values{}
+	if strings.HasPrefix(srv.Host, "/") {
+		q.Set("host", srv.Host) // unix socket
+	} else {
+		u.Host = srv.Host
+	}
+	q.Set("sslmode", srv.SSLMode())
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// SSLMode returns the libpq sslmode to connect to the server with:
+// "verify-ca" if the server has a CA cert, and "prefer" otherwise.
+// A client cert on its own doesn't change the mode.
+func (srv *SQLServer) SSLMode() string {
+	if srv.ServerCACert != "" {
+		return "verify-ca"
+	}
+	return "prefer"
+}
+
+// RedisConnString returns a connection URL for the Redis database
+// with the given Encore name: rediss:// if connections to the server
+// use TLS, redis:// if they don't, and unix:// for unix sockets.
+//
+// As with [Runtime.SQLConnString], certificates can't be passed in a URL,
+// so callers connecting with TLS must still add them to their TLS config.
+func (r *Runtime) RedisConnString(dbName string) (string, error) {
+	db, ok := r.RedisDatabase(dbName)
+	if !ok {
+		return "", fmt.Errorf("redis database %q: not found", dbName)
+	}
+	srv, ok := r.RedisServer(db.ServerID)
+	if !ok {
+		return "", fmt.Errorf("redis database %q: unknown server id %d", dbName, db.ServerID)
+	}
+	if err := validateRedisHost(srv.Host); err != nil {
+		return "", fmt.Errorf("redis database %q: %w", dbName, err)
+	}
+
+	u := &url.URL{Scheme: "redis"}
+	if srv.Password != "" {
+		u.User = url.UserPassword(srv.User, srv.Password)
+	} else if srv.User != "" {
+		u.User = url.User(srv.User)
+	}
+	switch {
+	case strings.HasPrefix(srv.Host, "/"):
+		u.Scheme = "unix"
+		u.Path = srv.Host
+		u.RawQuery = url.Values{"db": {strconv.Itoa(db.Database)}}.Encode()
+		return u.String(), nil
+	case srv.usesTLS():
+		u.Scheme = "rediss"
+	}
+	u.Host = srv.Host
+	u.Path = "/" + strconv.Itoa(db.Database)
+	return u.String(), nil
+}
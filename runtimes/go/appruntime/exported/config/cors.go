@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Normalize normalizes the allowed origins of the CORS config in place,
+// so that they match the Origin headers sent by browsers:
+// origins are lowercased, trailing slashes are removed, and
+// duplicates are dropped. Allowed methods are uppercased and
+// duplicate methods and headers are dropped as well.
+//
+// It reports an error if the wildcard origin "*" is allowed with credentials.
+// Use [UnsafeAllOriginWithCredentials] if that is really what's intended.
+// It also reports an error naming each allowed method that is not
+// a standard HTTP method and each header that is not a valid header name.
+func (c *CORS) Normalize() error {
+	if c == nil {
+		return nil
+	}
+	if slices.Contains(c.AllowOriginsWithCredentials, "*") {
+		return errors.New(`cors: the wildcard origin "*" cannot be allowed with credentials`)
+	}
+	c.AllowOriginsWithCredentials = normalizeOrigins(c.AllowOriginsWithCredentials)
+	c.AllowOriginsWithoutCredentials = normalizeOrigins(c.AllowOriginsWithoutCredentials)
+
+	var errs []error
+	c.AllowedMethods = normalizeMethods(c.AllowedMethods, &errs)
+	c.ExtraAllowedHeaders = normalizeHeaders("allowed", c.ExtraAllowedHeaders, &errs)
+	c.ExtraExposedHeaders = normalizeHeaders("exposed", c.ExtraExposedHeaders, &errs)
+	return errors.Join(errs...)
+}
+
+// httpMethods are the methods that may be listed in [CORS.AllowedMethods].
+var httpMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+// normalizeMethods returns the uppercased, deduplicated methods,
+// preserving their order. Unknown methods are reported to errs.
+func normalizeMethods(methods []string, errs *[]error) []string {
+	if methods == nil {
+		return nil
+	}
+	result := make([]string, 0, len(methods))
+	for _, m := range methods {
+		m = strings.ToUpper(strings.TrimSpace(m))
+		if !slices.Contains(httpMethods, m) {
+			*errs = append(*errs, fmt.Errorf("cors: allowed method %q: not a valid HTTP method", m))
+			continue
+		}
+		if !slices.Contains(result, m) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// normalizeHeaders returns the deduplicated headers, preserving their order.
+// Header names are compared case-insensitively, keeping the first spelling.
+// Invalid header names are reported to errs.
+func normalizeHeaders(kind string, headers []string, errs *[]error) []string {
+	if headers == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(headers))
+	result := make([]string, 0, len(headers))
+	for _, h := range headers {
+		h = strings.TrimSpace(h)
+		if h != "*" && !isHeaderToken(h) {
+			*errs = append(*errs, fmt.Errorf("cors: %s header %q: not a valid header name", kind, h))
+			continue
+		}
+		if key := strings.ToLower(h); !seen[key] {
+			seen[key] = true
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// isHeaderToken reports whether s is a valid header field name,
+// which is a token as defined by RFC 9110 section 5.6.2.
+func isHeaderToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeOrigins returns the normalized, deduplicated origins,
+// preserving their order.
+func normalizeOrigins(origins []string) []string {
+	if origins == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(origins))
+	result := make([]string, 0, len(origins))
+	for _, o := range origins {
+		if o != UnsafeAllOriginWithCredentials {
+			o = strings.ToLower(strings.TrimRight(o, "/"))
+		}
+		if !seen[o] {
+			seen[o] = true
+			result = append(result, o)
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCORSNormalize(t *testing.T) {
+	c := &CORS{
+		AllowOriginsWithCredentials: []string{
+			"https://App.Example.com/",
+			"https://app.example.com",
+			"https://*.example.com",
+			UnsafeAllOriginWithCredentials,
+		},
+		AllowOriginsWithoutCredentials: []string{"HTTP://LOCALHOST:3000//", "*", "*"},
+	}
+	if err := c.Normalize(); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+
+	wantCreds := []string{"https://app.example.com", "https://*.example.com", UnsafeAllOriginWithCredentials}
+	if !reflect.DeepEqual(c.AllowOriginsWithCredentials, wantCreds) {
+		t.Errorf("with credentials: got %q, want %q", c.AllowOriginsWithCredentials, wantCreds)
+	}
+	wantNoCreds := []string{"http://localhost:3000", "*"}
+	if !reflect.DeepEqual(c.AllowOriginsWithoutCredentials, wantNoCreds) {
+		t.Errorf("without credentials: got %q, want %q", c.AllowOriginsWithoutCredentials, wantNoCreds)
+	}
+
+	c = &CORS{AllowOriginsWithCredentials: []string{"https://app.example.com", "*"}}
+	if err := c.Normalize(); err == nil {
+		t.Error("wildcard with credentials: expected an error, got nil")
+	}
+
+	if err := (*CORS)(nil).Normalize(); err != nil {
+		t.Errorf("nil config: unexpected error: %v", err)
+	}
+}
+
+func TestCORSNormalizeMethodsAndHeaders(t *testing.T) {
+	c := &CORS{
+		AllowedMethods:      []string{"get", "POST", "GET", " put "},
+		ExtraAllowedHeaders: []string{"X-Custom", "x-custom", "*", "X-Other"},
+		ExtraExposedHeaders: []string{"X-Trace", "X-Trace"},
+	}
+	if err := c.Normalize(); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if want := []string{"GET", "POST", "PUT"}; !reflect.DeepEqual(c.AllowedMethods, want) {
+		t.Errorf("methods: got %q, want %q", c.AllowedMethods, want)
+	}
+	if want := []string{"X-Custom", "*", "X-Other"}; !reflect.DeepEqual(c.ExtraAllowedHeaders, want) {
+		t.Errorf("allowed headers: got %q, want %q", c.ExtraAllowedHeaders, want)
+	}
+	if want := []string{"X-Trace"}; !reflect.DeepEqual(c.ExtraExposedHeaders, want) {
+		t.Errorf("exposed headers: got %q, want %q", c.ExtraExposedHeaders, want)
+	}
+
+	tests := []struct {
+		name    string
+		cors    *CORS
+		wantErr string
+	}{
+		{
+			name:    "invalid method",
+			cors:    &CORS{AllowedMethods: []string{"GET", "GETS"}},
+			wantErr: `cors: allowed method "GETS": not a valid HTTP method`,
+		},
+		{
+			name:    "invalid allowed header",
+			cors:    &CORS{ExtraAllowedHeaders: []string{"X-Ok", "X Bad"}},
+			wantErr: `cors: allowed header "X Bad": not a valid header name`,
+		},
+		{
+			name:    "invalid exposed header",
+			cors:    &CORS{ExtraExposedHeaders: []string{"X-Bad:"}},
+			wantErr: `cors: exposed header "X-Bad:": not a valid header name`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cors.Normalize()
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRuntimeValidatedCORS(t *testing.T) {
+	cfg := fullRuntime()
+	cfg.CORS.AllowOriginsWithCredentials = []string{"https://App.Example.com/"}
+	config, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseRuntimeValidated(config, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeValidated: %v", err)
+	}
+	if want := []string{"https://app.example.com"}; !reflect.DeepEqual(got.CORS.AllowOriginsWithCredentials, want) {
+		t.Errorf("got %q, want %q", got.CORS.AllowOriginsWithCredentials, want)
+	}
+}
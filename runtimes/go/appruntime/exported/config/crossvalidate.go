@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// CrossValidate checks that the static and runtime configs are
+// consistent with each other, reporting every problem found joined
+// together using [errors.Join]:
+//
+//   - every service hosted according to the runtime config, or routed
+//     to by one of its gateways, must be bundled in the binary;
+//   - if the runtime config lists the hosted services, every bundled
+//     service must be either hosted or reachable through service discovery.
+//
+// The checks are skipped if the static config lists no services.
+func CrossValidate(static *Static, runtime *Runtime) error {
+	bundled := static.Services()
+	if len(bundled) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, svc := range runtime.HostedServiceNames() {
+		if !slices.Contains(bundled, svc) {
+			errs = append(errs, fmt.Errorf("service %q: hosted but not bundled in the binary", svc))
+		}
+	}
+	for _, gw := range runtime.Gateways {
+		for _, svc := range gw.Services {
+			if !slices.Contains(bundled, svc) {
+				errs = append(errs, fmt.Errorf("gateway %q: service %q is not bundled in the binary", gw.Name, svc))
+			}
+		}
+	}
+
+	if len(runtime.HostedServices) > 0 {
+		for _, svc := range bundled {
+			_, discoverable := runtime.ServiceDiscovery[svc]
+			if !slices.Contains(runtime.HostedServices, svc) && !discoverable {
+				errs = append(errs, fmt.Errorf("service %q: bundled but neither hosted nor reachable through service discovery", svc))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCrossValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(static *Static, runtime *Runtime)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(static *Static, runtime *Runtime) {},
+		},
+		{
+			name: "no_bundled_services",
+			modify: func(static *Static, runtime *Runtime) {
+				static.BundledServices = nil
+				runtime.HostedServices = []string{"orders"}
+			},
+		},
+		{
+			name: "hosted_not_bundled",
+			modify: func(static *Static, runtime *Runtime) {
+				runtime.HostedServices = append(runtime.HostedServices, "orders")
+			},
+			wantErr: `service "orders": hosted but not bundled in the binary`,
+		},
+		{
+			name: "gateway_service_not_bundled",
+			modify: func(static *Static, runtime *Runtime) {
+				runtime.Gateways[0].Services = append(runtime.Gateways[0].Services, "orders")
+			},
+			wantErr: `gateway "api-gateway": service "orders" is not bundled in the binary`,
+		},
+		{
+			name: "bundled_not_hosted",
+			modify: func(static *Static, runtime *Runtime) {
+				static.BundledServices = append(static.BundledServices, "orders")
+			},
+			wantErr: `service "orders": bundled but neither hosted nor reachable through service discovery`,
+		},
+		{
+			name: "all_hosted",
+			modify: func(static *Static, runtime *Runtime) {
+				static.BundledServices = append(static.BundledServices, "orders")
+				runtime.HostedServices = nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			static := &Static{BundledServices: []string{"users", "billing"}}
+			runtime := fullRuntime()
+			tt.modify(static, runtime)
+			err := CrossValidate(static, runtime)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
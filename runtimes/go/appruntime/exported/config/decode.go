@@ -0,0 +1,370 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/ascii85"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// errRawJSONConfig is reported when decoding a config that
+// looks like raw JSON rather than an encoded config.
+var errRawJSONConfig = errors.New("config appears to be raw JSON rather than base64-encoded; was it already decoded?")
+
+// errUnsupportedEncoding is reported when decoding a config
+// that starts with a UTF-16 byte order mark.
+var errUnsupportedEncoding = errors.New("unsupported encoding: config is UTF-16 encoded; it must be ASCII or UTF-8")
+
+// errConfigTooLarge is reported when a config exceeds the size limit
+// set using [WithMaxConfigBytes].
+var errConfigTooLarge = errors.New("config is too large")
+
+// configEncodings are the base64 encodings accepted for configs,
+// in the order they are tried.
+//
+// We used to use RawURLEncoding, but now we use StdEncoding.
+// The remaining variants are accepted for configs produced by other tools.
+var configEncodings = []struct {
+	name string // reported in ParseInfo.Encoding
+	enc  *base64.Encoding
+}{
+	{"base64", base64.StdEncoding},
+	{"base64-raw-url", base64.RawURLEncoding},
+	{"base64-raw", base64.RawStdEncoding},
+	{"base64-url", base64.URLEncoding},
+}
+
+// encodingASCII85 is the ParseInfo.Encoding of ascii85-encoded configs.
+const encodingASCII85 = "ascii85"
+
+// A Decoder decodes configs delivered in a transport encoding,
+// such as base64. Decoders are tried in order by the parse functions;
+// see [RegisterDecoder].
+//
+// Decoders only undo the transport encoding: the decoded config
+// may still be compressed, which is handled separately.
+type Decoder interface {
+	// Name is the name of the encoding, reported in ParseInfo.Encoding.
+	Name() string
+
+	// CanDecode reports whether s, with surrounding quotes and
+	// whitespace removed, looks like a config in this encoding.
+	CanDecode(s string) bool
+
+	// Decode decodes s, with surrounding quotes and whitespace removed.
+	Decode(s string) ([]byte, error)
+}
+
+// limitedDecoder is implemented by decoders that can stop decoding
+// early once the decoded config exceeds maxBytes, reporting an error
+// wrapping errConfigTooLarge.
+type limitedDecoder interface {
+	decodeLimited(s string, maxBytes int) ([]byte, error)
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = builtinDecoders()
+)
+
+// builtinDecoders returns the decoders for configEncodings,
+// followed by the decoder for framed ascii85.
+func builtinDecoders() []Decoder {
+	ds := make([]Decoder, 0, len(configEncodings)+1)
+	for _, ce := range configEncodings {
+		ds = append(ds, base64Decoder{name: ce.name, enc: ce.enc})
+	}
+	return append(ds, ascii85Decoder{})
+}
+
+// RegisterDecoder adds d to the end of the decoders tried when parsing
+// a config, after the built-in base64 and ascii85 decoders and any
+// decoders registered before it. Since the base64 decoders accept any
+// valid base64, d is only used for configs that aren't valid base64.
+//
+// It is meant to be called from an init function.
+// It panics if d is nil or if a decoder with the same name is
+// already registered.
+func RegisterDecoder(d Decoder) {
+	if d == nil {
+		panic("config: RegisterDecoder decoder is nil")
+	}
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	for _, existing := range decoders {
+		if existing.Name() == d.Name() {
+			panic("config: RegisterDecoder called twice for decoder " + d.Name())
+		}
+	}
+	decoders = append(decoders, d)
+}
+
+// registeredDecoders returns the decoders to try, in order.
+func registeredDecoders() []Decoder {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	return decoders
+}
+
+// base64Decoder decodes configs using a base64 encoding.
+type base64Decoder struct {
+	name string
+	enc  *base64.Encoding
+}
+
+func (d base64Decoder) Name() string          { return d.name }
+func (d base64Decoder) CanDecode(string) bool { return true }
+
+func (d base64Decoder) Decode(s string) ([]byte, error) {
+	// nosemgrep
+	return d.enc.DecodeString(s)
+}
+
+// ascii85Decoder decodes ascii85-encoded configs framed
+// by "<~" and "~>".
+type ascii85Decoder struct{}
+
+func (ascii85Decoder) Name() string { return encodingASCII85 }
+
+func (ascii85Decoder) CanDecode(s string) bool {
+	return strings.HasPrefix(s, ascii85Prefix) && strings.HasSuffix(s, ascii85Suffix) &&
+		len(s) >= len(ascii85Prefix)+len(ascii85Suffix)
+}
+
+func (d ascii85Decoder) Decode(s string) ([]byte, error) {
+	return d.decodeLimited(s, 0)
+}
+
+func (ascii85Decoder) decodeLimited(s string, maxBytes int) ([]byte, error) {
+	return decodeASCII85(s[len(ascii85Prefix):len(s)-len(ascii85Suffix)], maxBytes)
+}
+
+// decodeConfig decodes a config using the registered decoders, trying
+// each one that can decode it in order and returning the first successful
+// result. By default these are the base64 encodings in configEncodings,
+// followed by ascii85.
+//
+// There's no need to try the remaining encodings if the result of the
+// first successful one fails to parse: the variants only differ in
+// '+' and '/' versus '-' and '_', and in padding, so a config accepted
+// by more than one of them contains none of those characters and
+// decodes to the same bytes with each one.
+//
+// Surrounding quotes and all ASCII whitespace are stripped first, since
+// configs pasted through a shell often pick those up along the way,
+// as is a leading UTF-8 byte order mark, which some Windows tooling adds.
+// A config starting with a UTF-16 byte order mark is rejected with
+// errUnsupportedEncoding.
+// A config that is raw JSON rather than encoded is rejected with
+// errRawJSONConfig.
+//
+// If decoding fails it returns the error from the first decoder tried,
+// which is StdEncoding, noting when the config looks like it was cut short.
+//
+// If maxBytes is positive, configs that decode to more than maxBytes
+// bytes are rejected with errConfigTooLarge.
+func decodeConfig(s string, maxBytes int) ([]byte, error) {
+	data, _, err := decodeConfigEncoding(s, maxBytes)
+	return data, err
+}
+
+// decodeConfigEncoding is like decodeConfig but also returns
+// the name of the encoding that was used to decode the config.
+func decodeConfigEncoding(s string, maxBytes int) (data []byte, encoding string, err error) {
+	if strings.HasPrefix(s, "\xff\xfe") || strings.HasPrefix(s, "\xfe\xff") {
+		return nil, "", errUnsupportedEncoding
+	}
+	cleaned := cleanConfig(s)
+	if strings.HasPrefix(cleaned, "{") {
+		// '{' is not part of any base64 alphabet, so this is most likely
+		// a config that has already been decoded.
+		return nil, "", errRawJSONConfig
+	}
+
+	var firstErr error
+	for _, d := range registeredDecoders() {
+		if !d.CanDecode(cleaned) {
+			continue
+		}
+		data, err := decodeWith(d, cleaned, maxBytes)
+		if err == nil || errors.Is(err, errConfigTooLarge) {
+			return data, d.Name(), err
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = errors.New("no decoder accepts the config")
+	}
+	if isTruncatedBase64(cleaned, firstErr) {
+		firstErr = fmt.Errorf("%w: the config may have been truncated (got %d characters, which is not a multiple of 4)",
+			firstErr, len(cleaned))
+	}
+	if cleaned != s {
+		return nil, "", fmt.Errorf("%w (after stripping surrounding quotes and whitespace)", firstErr)
+	}
+	return nil, "", firstErr
+}
+
+// decodeWith decodes s using d, rejecting configs that decode
+// to more than maxBytes bytes if it is positive.
+func decodeWith(d Decoder, s string, maxBytes int) ([]byte, error) {
+	if ld, ok := d.(limitedDecoder); ok {
+		return ld.decodeLimited(s, maxBytes)
+	}
+	data, err := d.Decode(s)
+	if err == nil && maxBytes > 0 && len(data) > maxBytes {
+		return nil, fmt.Errorf("%w: decodes to %d bytes, more than the limit of %d", errConfigTooLarge, len(data), maxBytes)
+	}
+	return data, err
+}
+
+// isTruncatedBase64 reports whether err, the error from decoding s
+// using StdEncoding, is consistent with s having been truncated:
+// s is not a whole number of 4-character quanta, and decoding
+// failed within the final, incomplete one.
+//
+// Environment variables are sometimes cut off by the platform,
+// so it's worth saying so rather than just reporting an illegal byte.
+func isTruncatedBase64(s string, err error) bool {
+	var corrupt base64.CorruptInputError
+	if !errors.As(err, &corrupt) || len(s)%4 == 0 {
+		return false
+	}
+	return int(corrupt) >= len(s)-len(s)%4
+}
+
+// cleanConfig removes a leading UTF-8 byte order mark and all ASCII
+// whitespace from s, as well as a pair of matching single or double
+// quotes surrounding it.
+func cleanConfig(s string) string {
+	s = strings.TrimPrefix(s, "\ufeff")
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+// The delimiters that frame an ascii85-encoded config.
+//
+// Neither '<' nor '~' is part of any base64 alphabet, so a framed
+// ascii85 config is never mistaken for base64 or vice versa.
+const (
+	ascii85Prefix = "<~"
+	ascii85Suffix = "~>"
+)
+
+// decodeASCII85 decodes the ascii85-encoded s, with the
+// framing "<~" and "~>" already removed.
+//
+// If maxBytes is positive and s decodes to more than maxBytes bytes,
+// it reports an error wrapping errConfigTooLarge.
+func decodeASCII85(s string, maxBytes int) ([]byte, error) {
+	// Each 'z' in the input expands to four bytes,
+	// so this is an upper bound on the decoded size.
+	size := 4 * len(s)
+	if maxBytes > 0 && size > maxBytes+4 {
+		// Decode stops early once dst is full, leaving room
+		// for the last group to tell if the limit is exceeded.
+		size = maxBytes + 4
+	}
+	dst := make([]byte, size)
+	n, nsrc, err := ascii85.Decode(dst, []byte(s), true)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes > 0 && (n > maxBytes || nsrc < len(s)) {
+		return nil, fmt.Errorf("%w: decodes to more than the limit of %d bytes", errConfigTooLarge, maxBytes)
+	}
+	return dst[:n], nil
+}
+
+// Format tags that may prefix a decoded config to say how it is encoded.
+//
+// Tags are below any byte a legacy config can start with, which is either
+// JSON (starting with '{' or whitespace) or a gzip stream (starting with
+// gzipMagic), so untagged configs are still recognized.
+const (
+	formatJSON     byte = 0x00 // JSON
+	formatGzipJSON byte = 0x01 // gzip-compressed JSON
+
+	// maxFormatTag is the largest byte reserved for format tags.
+	// It's just below '\t', the smallest byte a JSON document can start with.
+	maxFormatTag byte = 0x08
+)
+
+// unwrapConfig returns the JSON config in data, handling both
+// format-tagged configs and legacy untagged ones.
+//
+// If maxBytes is positive, configs that decompress to more than
+// maxBytes bytes are rejected with errConfigTooLarge.
+func unwrapConfig(data []byte, maxBytes int) ([]byte, error) {
+	if len(data) == 0 || data[0] > maxFormatTag {
+		return decompressConfig(data, maxBytes)
+	}
+
+	switch tag, payload := data[0], data[1:]; tag {
+	case formatJSON:
+		return payload, nil
+	case formatGzipJSON:
+		if !bytes.HasPrefix(payload, gzipMagic) {
+			return nil, errors.New("config is tagged as gzip-compressed but is not")
+		}
+		return decompressConfig(payload, maxBytes)
+	default:
+		return nil, fmt.Errorf("unknown config format tag 0x%02x", tag)
+	}
+}
+
+// isCompressedConfig reports whether the decoded config in data
+// is gzip-compressed, with or without a format tag.
+func isCompressedConfig(data []byte) bool {
+	if len(data) > 0 && data[0] == formatGzipJSON {
+		return true
+	}
+	return bytes.HasPrefix(data, gzipMagic)
+}
+
+// gzipMagic is the header that starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompressConfig gunzips data if it is gzip-compressed,
+// and otherwise returns it unchanged.
+//
+// If maxBytes is positive, at most maxBytes bytes are decompressed,
+// and larger configs are rejected with errConfigTooLarge.
+func decompressConfig(data []byte, maxBytes int) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	if maxBytes <= 0 {
+		return io.ReadAll(zr)
+	}
+
+	// Read one byte more than the limit to tell if it's exceeded.
+	lr := &io.LimitedReader{R: zr, N: int64(maxBytes) + 1}
+	out, err := io.ReadAll(lr)
+	if err != nil {
+		return nil, err
+	} else if len(out) > maxBytes {
+		return nil, fmt.Errorf("%w: decompresses to more than the limit of %d bytes", errConfigTooLarge, maxBytes)
+	}
+	return out, nil
+}
@@ -0,0 +1,324 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/ascii85"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	// The payload is chosen so that its encodings need padding and
+	// contain characters that differ between the std and url alphabets.
+	want := []byte(`{"app_slug":"~~~???>>>!"}`)
+
+	tests := []struct {
+		name string
+		enc  *base64.Encoding
+	}{
+		{"std", base64.StdEncoding},
+		{"raw_url", base64.RawURLEncoding},
+		{"raw_std", base64.RawStdEncoding},
+		{"url", base64.URLEncoding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeConfig(tt.enc.EncodeToString(want), 0)
+			if err != nil {
+				t.Fatalf("decodeConfig: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+
+	if _, err := decodeConfig("not base64!", 0); err == nil {
+		t.Error("undecodable input: expected an error, got nil")
+	}
+}
+
+func TestDecodeConfigASCII85(t *testing.T) {
+	want := []byte(`{"app_slug":"app"}`)
+	buf := make([]byte, ascii85.MaxEncodedLen(len(want)))
+	encoded := string(buf[:ascii85.Encode(buf, want)])
+
+	got, err := decodeConfig("<~"+encoded+"~>", 0)
+	if err != nil {
+		t.Fatalf("framed ascii85: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("framed ascii85: got %q, want %q", got, want)
+	}
+
+	// Valid base64 is always decoded as base64, even when it
+	// would also be valid (unframed) ascii85.
+	b64 := base64.StdEncoding.EncodeToString(want)
+	if got, err := decodeConfig(b64, 0); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("base64: got %q, %v, want %q", got, err, want)
+	}
+
+	// Unframed or corrupt ascii85 reports the base64 error.
+	var corrupt base64.CorruptInputError
+	for _, s := range []string{encoded, "<~" + encoded, "<~{}~>"} {
+		if _, err := decodeConfig(s, 0); !errors.As(err, &corrupt) {
+			t.Errorf("decodeConfig(%q): got %v, want a base64.CorruptInputError", s, err)
+		}
+	}
+}
+
+func TestDecodeConfigCleaning(t *testing.T) {
+	want := []byte(`{"app_slug":"~~~???>>>!"}`)
+	encoded := base64.StdEncoding.EncodeToString(want)
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"embedded_newlines", encoded[:10] + "\n" + encoded[10:20] + "\r\n\t" + encoded[20:] + "\n"},
+		{"double_quoted", ` "` + encoded + `"` + "\n"},
+		{"single_quoted", `'` + encoded + `'`},
+		{"quoted_ascii85", `"<~ ` + "9jqo^BlbD-BleB1DJ+*+F(f,q" + ` ~>"`},
+		{"utf8_bom", "\ufeff" + encoded + "\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeConfig(tt.input, 0); err != nil {
+				t.Fatalf("decodeConfig: %v", err)
+			}
+		})
+	}
+
+	if got, err := decodeConfig(tests[0].input, 0); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("got %q, %v, want %q", got, err, want)
+	}
+
+	// A genuinely corrupt config still fails, and the error
+	// says that stripping was attempted.
+	_, err := decodeConfig("\""+encoded[:10]+"!!"+encoded[10:]+"\"", 0)
+	var corrupt base64.CorruptInputError
+	if !errors.As(err, &corrupt) {
+		t.Fatalf("corrupt: got %v, want a base64.CorruptInputError", err)
+	}
+	if !strings.Contains(err.Error(), "after stripping surrounding quotes and whitespace") {
+		t.Errorf("corrupt: error %q does not mention stripping", err)
+	}
+
+	// Mismatched quotes are left alone.
+	if _, err := decodeConfig(`"`+encoded+`'`, 0); err == nil {
+		t.Error("mismatched quotes: expected an error, got nil")
+	}
+}
+
+func TestDecodeConfigAmbiguous(t *testing.T) {
+	// This config is valid in every base64 alphabet, since it has neither
+	// padding nor any of the characters the alphabets disagree on.
+	const (
+		encoded = "eyJhcHBfaWQiOiJhcHAiLCJhcGlfYmFzZV91cmwiOiJodHRwczovL2V4YW1wbGUuY29tIiwiYXBwX3NsdWciOiJhYSJ9"
+		want    = `{"app_id":"app","api_base_url":"https://example.com","app_slug":"aa"}`
+	)
+	for _, ce := range configEncodings {
+		got, err := ce.enc.DecodeString(encoded)
+		if err != nil || string(got) != want {
+			t.Errorf("%s: got %q, %v, want %q", ce.name, got, err, want)
+		}
+	}
+
+	cfg, err := ParseRuntimeErr(encoded, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+	if cfg.AppSlug != "aa" {
+		t.Errorf("got app slug %q, want %q", cfg.AppSlug, "aa")
+	}
+}
+
+func TestDecodeConfigTruncated(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(`{"app_slug":"app","env_name":"prod"}`))
+
+	// Cut off so that no base64 variant can decode it.
+	truncated := encoded[:len(encoded)-len(encoded)%4-3]
+	_, err := decodeConfig(truncated, 0)
+	if err == nil {
+		t.Fatal("truncated: expected an error, got nil")
+	}
+	wantMsg := fmt.Sprintf("may have been truncated (got %d characters", len(truncated))
+	if !strings.Contains(err.Error(), wantMsg) {
+		t.Errorf("truncated: error %q does not contain %q", err, wantMsg)
+	}
+	var corrupt base64.CorruptInputError
+	if !errors.As(err, &corrupt) {
+		t.Errorf("truncated: got %v, want a base64.CorruptInputError", err)
+	}
+
+	// A corrupt byte in the middle is not a truncation,
+	// even if the length is also off.
+	corruptCfg := encoded[:8] + "!" + encoded[8:]
+	_, err = decodeConfig(corruptCfg, 0)
+	if err == nil {
+		t.Fatal("corrupt: expected an error, got nil")
+	}
+	if strings.Contains(err.Error(), "truncated") {
+		t.Errorf("corrupt: error %q should not mention truncation", err)
+	}
+}
+
+func TestDecodeConfigRawJSON(t *testing.T) {
+	for _, input := range []string{
+		`{"app_slug":"app"}`,
+		"  {\n  \"app_slug\": \"app\"\n}\n",
+		`'{"app_slug":"app"}'`,
+	} {
+		_, err := decodeConfig(input, 0)
+		if !errors.Is(err, errRawJSONConfig) {
+			t.Errorf("decodeConfig(%q): got %v, want %v", input, err, errRawJSONConfig)
+		}
+	}
+}
+
+func TestDecodeConfigUTF16(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(`{"app_slug":"app"}`))
+	for _, bom := range []string{"\xff\xfe", "\xfe\xff"} {
+		_, err := decodeConfig(bom+encoded, 0)
+		if !errors.Is(err, errUnsupportedEncoding) {
+			t.Errorf("bom %q: got %v, want %v", bom, err, errUnsupportedEncoding)
+		}
+		if err != nil && !strings.Contains(err.Error(), "unsupported encoding") {
+			t.Errorf("bom %q: error %q does not mention the unsupported encoding", bom, err)
+		}
+	}
+}
+
+// prefixDecoder is a test decoder for configs of the form prefix+json.
+type prefixDecoder struct {
+	name, prefix string
+}
+
+func (d prefixDecoder) Name() string { return d.name }
+
+func (d prefixDecoder) CanDecode(s string) bool { return strings.HasPrefix(s, d.prefix) }
+
+func (d prefixDecoder) Decode(s string) ([]byte, error) {
+	return []byte(strings.TrimPrefix(s, d.prefix)), nil
+}
+
+// withDecoders restores the registered decoders when t completes.
+func withDecoders(t *testing.T) {
+	t.Helper()
+	orig := registeredDecoders()
+	t.Cleanup(func() {
+		decodersMu.Lock()
+		decoders = orig
+		decodersMu.Unlock()
+	})
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	withDecoders(t)
+	RegisterDecoder(prefixDecoder{name: "first", prefix: "raw:"})
+	RegisterDecoder(prefixDecoder{name: "second", prefix: "raw:"})
+	RegisterDecoder(prefixDecoder{name: "other", prefix: "other:"})
+
+	// The custom decoders participate in the chain, in order.
+	data, encoding, err := decodeConfigEncoding(`raw:[1,2]`, 0)
+	if err != nil || string(data) != "[1,2]" || encoding != "first" {
+		t.Errorf("raw: got %q, %q, %v, want %q, %q", data, encoding, err, "[1,2]", "first")
+	}
+	data, encoding, err = decodeConfigEncoding(`other:[3]`, 0)
+	if err != nil || string(data) != "[3]" || encoding != "other" {
+		t.Errorf("other: got %q, %q, %v, want %q, %q", data, encoding, err, "[3]", "other")
+	}
+
+	// The built-in decoders still come first.
+	b64 := base64.StdEncoding.EncodeToString([]byte(`{}`))
+	if _, encoding, err := decodeConfigEncoding(b64, 0); err != nil || encoding != "base64" {
+		t.Errorf("base64: got %q, %v, want %q", encoding, err, "base64")
+	}
+
+	// The size limit applies to custom decoders too.
+	if _, _, err := decodeConfigEncoding(`raw:[1,2,3,4]`, 4); !errors.Is(err, errConfigTooLarge) {
+		t.Errorf("limit: got %v, want %v", err, errConfigTooLarge)
+	}
+
+	// Custom decoders are used when parsing.
+	cfg, err := ParseRuntimeErr(`raw:{"app_id":"app","api_base_url":"https://example.com"}`, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+	if cfg.AppID != "app" {
+		t.Errorf("got app id %q, want %q", cfg.AppID, "app")
+	}
+}
+
+func TestRegisterDecoderDuplicate(t *testing.T) {
+	withDecoders(t)
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic registering a duplicate decoder")
+		}
+	}()
+	RegisterDecoder(prefixDecoder{name: "base64", prefix: "x"})
+}
+
+func TestUnwrapConfig(t *testing.T) {
+	want := []byte(`{"app_slug":"app"}`)
+	gz := gzipJSON(t, string(want))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{name: "legacy_json", data: want},
+		{name: "legacy_gzip", data: gz},
+		{name: "tagged_json", data: append([]byte{0x00}, want...)},
+		{name: "tagged_gzip", data: append([]byte{0x01}, gz...)},
+		{name: "tagged_gzip_not_compressed", data: append([]byte{0x01}, want...), wantErr: "tagged as gzip-compressed"},
+		{name: "unknown_tag", data: append([]byte{0x02}, want...), wantErr: "unknown config format tag 0x02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unwrapConfig(tt.data, 0)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unwrapConfig: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+
+	// Tagged configs parse end to end.
+	config := base64.StdEncoding.EncodeToString(append([]byte{0x01}, gzipJSON(t, `{"app_id":"app","api_base_url":"https://example.com"}`)...))
+	if cfg, err := ParseRuntimeErr(config, ""); err != nil || cfg.AppID != "app" {
+		t.Errorf("ParseRuntimeErr: got %+v, %v", cfg, err)
+	}
+	config = base64.StdEncoding.EncodeToString([]byte{0x07, '{', '}'})
+	var perr *ParseError
+	if _, err := ParseRuntimeErr(config, ""); !errors.As(err, &perr) || perr.Stage != StageDecode {
+		t.Errorf("unknown tag: got %v, want a decode-stage *ParseError", err)
+	}
+}
+
+func gzipJSON(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
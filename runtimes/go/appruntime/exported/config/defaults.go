@@ -0,0 +1,51 @@
+package config
+
+import "strings"
+
+// ApplyDefaults fills in unset fields of the runtime config with their
+// documented default values:
+//
+//   - EnvType defaults to "production".
+//   - APIBaseURL defaults to the https scheme if only a host is given.
+//   - GracefulShutdown defaults to the timings the runtime falls back to,
+//     as reported by [Runtime.ShutdownPhases], so filling them in doesn't
+//     change how the app shuts down. With neither it nor ShutdownTimeout
+//     set, the force shutdown begins after 5 seconds and handlers are
+//     canceled after 4, for a total of 6 seconds including the grace period.
+//
+// Fields that are already set are left unchanged.
+// It returns [ErrFrozen] without modifying r if r is frozen.
+func (r *Runtime) ApplyDefaults() error {
+	if r.frozen {
+		return ErrFrozen
+	}
+	if r.EnvType == "" {
+		r.EnvType = EnvProduction
+	}
+	if r.APIBaseURL != "" && !strings.Contains(r.APIBaseURL, "://") {
+		r.APIBaseURL = "https://" + r.APIBaseURL
+	}
+
+	// Fill in the timings the runtime would fall back to, so that
+	// they're explicit without changing how the app shuts down.
+	phases := r.ShutdownPhases()
+	if r.GracefulShutdown == nil {
+		r.GracefulShutdown = &GracefulShutdownTimings{}
+	}
+	gs := r.GracefulShutdown
+	if gs.Total == nil {
+		// The runtime begins the force shutdown a grace period
+		// before the total runs out.
+		total := Duration(phases.ForceShutdownAfter + ForceShutdownGrace)
+		gs.Total = &total
+	}
+	if gs.ShutdownHooks == nil {
+		hooks := Duration(phases.ForceShutdownAfter)
+		gs.ShutdownHooks = &hooks
+	}
+	if gs.Handlers == nil {
+		handlers := Duration(phases.CancelHandlersAfter)
+		gs.Handlers = &handlers
+	}
+	return nil
+}
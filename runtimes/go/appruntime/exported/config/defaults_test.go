@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	t.Run("needs_default", func(t *testing.T) {
+		cfg := &Runtime{APIBaseURL: "api.example.com"}
+		cfg.ApplyDefaults()
+
+		if cfg.EnvType != "production" {
+			t.Errorf("EnvType: got %q, want %q", cfg.EnvType, "production")
+		}
+		if cfg.APIBaseURL != "https://api.example.com" {
+			t.Errorf("APIBaseURL: got %q, want %q", cfg.APIBaseURL, "https://api.example.com")
+		}
+		gs := cfg.GracefulShutdown
+		if gs == nil || gs.Total == nil || gs.ShutdownHooks == nil || gs.Handlers == nil {
+			t.Fatalf("GracefulShutdown: got %+v, want all timings set", gs)
+		}
+		// The defaults match the runtime's fallbacks.
+		if gs.Total.Std() != 6*time.Second || gs.ShutdownHooks.Std() != 5*time.Second || gs.Handlers.Std() != 4*time.Second {
+			t.Errorf("GracefulShutdown: got total=%v hooks=%v handlers=%v", *gs.Total, *gs.ShutdownHooks, *gs.Handlers)
+		}
+		if got, want := cfg.ShutdownPhases(), (&Runtime{}).ShutdownPhases(); got != want {
+			t.Errorf("ShutdownPhases: got %+v, want %+v as without defaults", got, want)
+		}
+		if err := errors.Join(cfg.validateGracefulShutdown()...); err != nil {
+			t.Errorf("defaults don't validate: %v", err)
+		}
+	})
+
+	t.Run("shutdown_timeout_fallback", func(t *testing.T) {
+		cfg := &Runtime{ShutdownTimeout: Duration(3 * time.Second)}
+		want := cfg.ShutdownPhases()
+		cfg.ApplyDefaults()
+		if got := cfg.GracefulShutdown.Total.Std(); got != 4*time.Second {
+			t.Errorf("GracefulShutdown.Total: got %v, want %v", got, 4*time.Second)
+		}
+		if got := cfg.GracefulShutdown.Handlers.Std(); got != 2*time.Second {
+			t.Errorf("GracefulShutdown.Handlers: got %v, want %v", got, 2*time.Second)
+		}
+		if got := cfg.ShutdownPhases(); got != want {
+			t.Errorf("ShutdownPhases: got %+v, want %+v as without defaults", got, want)
+		}
+	})
+
+	t.Run("already_set", func(t *testing.T) {
+		cfg := fullRuntime()
+		cfg.ApplyDefaults()
+		want := fullRuntime()
+
+		if cfg.EnvType != want.EnvType || cfg.APIBaseURL != want.APIBaseURL {
+			t.Errorf("got env type %q, api base url %q; want %q, %q", cfg.EnvType, cfg.APIBaseURL, want.EnvType, want.APIBaseURL)
+		}
+		if *cfg.GracefulShutdown.Total != *want.GracefulShutdown.Total ||
+			*cfg.GracefulShutdown.ShutdownHooks != *want.GracefulShutdown.ShutdownHooks ||
+			*cfg.GracefulShutdown.Handlers != *want.GracefulShutdown.Handlers {
+			t.Errorf("GracefulShutdown was modified: got %+v", cfg.GracefulShutdown)
+		}
+	})
+}
+
+func TestParseRuntimeWithDefaults(t *testing.T) {
+	config := encodeJSON(t, map[string]any{"api_base_url": "api.example.com"})
+	cfg, err := ParseRuntimeWithDefaults(config, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeWithDefaults: %v", err)
+	}
+	if cfg.APIBaseURL != "https://api.example.com" || cfg.EnvType != "production" {
+		t.Errorf("defaults not applied: got api base url %q, env type %q", cfg.APIBaseURL, cfg.EnvType)
+	}
+}
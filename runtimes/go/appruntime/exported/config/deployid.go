@@ -0,0 +1,20 @@
+package config
+
+import "strings"
+
+// EffectiveDeployID returns the deploy ID to use given an override,
+// such as one provided by the ENCORE_DEPLOY_ID environment variable.
+// The override is trimmed of surrounding whitespace, and takes precedence
+// over the deploy ID in the config if it's non-empty after trimming.
+func (r *Runtime) EffectiveDeployID(override string) string {
+	return resolveDeployID(r.DeployID, override)
+}
+
+// resolveDeployID returns override, trimmed of surrounding whitespace,
+// if it is set, and embedded otherwise.
+func resolveDeployID(embedded, override string) string {
+	if override = strings.TrimSpace(override); override != "" {
+		return override
+	}
+	return embedded
+}
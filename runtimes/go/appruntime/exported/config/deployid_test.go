@@ -0,0 +1,61 @@
+package config
+
+import "testing"
+
+func TestEffectiveDeployID(t *testing.T) {
+	tests := []struct {
+		name     string
+		embedded string
+		override string
+		want     string
+	}{
+		{"both_empty", "", "", ""},
+		{"only_embedded", "embedded", "", "embedded"},
+		{"only_override", "", "override", "override"},
+		{"both_set", "embedded", "override", "override"},
+		{"whitespace_override", "embedded", " \t\n", "embedded"},
+		{"padded_override", "embedded", "  override\n", "override"},
+		{"empty_override", "embedded", "", "embedded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Runtime{DeployID: tt.embedded}
+			if got := cfg.EffectiveDeployID(tt.override); got != tt.want {
+				t.Errorf("EffectiveDeployID(%q) = %q, want %q", tt.override, got, tt.want)
+			}
+			if cfg.DeployID != tt.embedded {
+				t.Errorf("EffectiveDeployID modified the config")
+			}
+		})
+	}
+}
+
+func TestParseRuntimeWhitespaceDeployID(t *testing.T) {
+	config, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for override, want := range map[string]string{
+		"   ":          "deploy-id",
+		" override \n": "override",
+	} {
+		cfg, err := ParseRuntimeErr(config, override)
+		if err != nil {
+			t.Fatalf("ParseRuntimeErr: %v", err)
+		}
+		if cfg.DeployID != want {
+			t.Errorf("override %q: got deploy id %q, want %q", override, cfg.DeployID, want)
+		}
+	}
+
+	// A whitespace-only source doesn't stop later sources being tried.
+	t.Setenv("TEST_DEPLOY_ID_BLANK", "  ")
+	t.Setenv("TEST_DEPLOY_ID_SET", "from-env")
+	cfg, err := ParseRuntimeOptions(config, WithDeployIDSources("TEST_DEPLOY_ID_BLANK", "TEST_DEPLOY_ID_SET"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DeployID != "from-env" {
+		t.Errorf("sources: got deploy id %q, want %q", cfg.DeployID, "from-env")
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// reportDeprecated calls report with the path of each populated field
+// in v that is tagged `encore:"deprecated"`.
+//
+// Paths use the JSON field names, such as "shutdown_timeout" or
+// "sql_databases[0].some_field", so they match what the config author wrote.
+func reportDeprecated(v any, report func(field string)) {
+	walkDeprecated(reflect.ValueOf(v), "", report)
+}
+
+func walkDeprecated(v reflect.Value, path string, report func(string)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			walkDeprecated(v.Elem(), path, report)
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			fv := v.Field(i)
+			name := joinPath(path, jsonFieldName(f))
+			if isDeprecated(f) && !fv.IsZero() {
+				report(name)
+			}
+			walkDeprecated(fv, name, report)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkDeprecated(v.Index(i), path+"["+strconv.Itoa(i)+"]", report)
+		}
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(a.String(), b.String())
+		})
+		for _, k := range keys {
+			walkDeprecated(v.MapIndex(k), path+"["+strconv.Quote(k.String())+"]", report)
+		}
+	}
+}
+
+// isDeprecated reports whether f is tagged `encore:"deprecated"`.
+func isDeprecated(f reflect.StructField) bool {
+	for _, opt := range strings.Split(f.Tag.Get("encore"), ",") {
+		if opt == "deprecated" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonFieldName returns the name f is encoded as in JSON.
+func jsonFieldName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return f.Name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
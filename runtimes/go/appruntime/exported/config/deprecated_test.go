@@ -0,0 +1,68 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReportDeprecated(t *testing.T) {
+	type inner struct {
+		Old string `json:"old" encore:"deprecated"`
+		New string `json:"new"`
+	}
+	type outer struct {
+		Legacy  int               `json:"legacy,omitempty" encore:"deprecated"`
+		Unset   int               `json:"unset" encore:"deprecated"`
+		Inner   *inner            `json:"inner"`
+		List    []inner           `json:"list"`
+		ByName  map[string]*inner `json:"by_name"`
+		NoTag   string            `encore:"deprecated"`
+		current string            `encore:"deprecated"`
+	}
+
+	v := &outer{
+		Legacy:  1,
+		Inner:   &inner{Old: "x"},
+		List:    []inner{{New: "y"}, {Old: "z"}},
+		ByName:  map[string]*inner{"b": {Old: "b"}, "a": {Old: "a"}},
+		NoTag:   "set",
+		current: "ignored",
+	}
+	var got []string
+	reportDeprecated(v, func(field string) { got = append(got, field) })
+
+	want := []string{
+		"legacy",
+		"inner.old",
+		"list[1].old",
+		`by_name["a"].old`,
+		`by_name["b"].old`,
+		"NoTag",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithDeprecationHandler(t *testing.T) {
+	config := encodeJSON(t, map[string]any{
+		"api_base_url":     "https://example.com",
+		"shutdown_timeout": "5s",
+	})
+
+	var got []string
+	cfg, err := ParseRuntimeOptions(config, WithDeprecationHandler(func(field string) {
+		got = append(got, field)
+	}))
+	if err != nil {
+		t.Fatalf("ParseRuntimeOptions: %v", err)
+	}
+	if want := []string{"shutdown_timeout"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got deprecated fields %q, want %q", got, want)
+	}
+
+	// The deprecated field is still used.
+	if cfg.ShutdownTimeout == 0 {
+		t.Error("ShutdownTimeout was not parsed")
+	}
+}
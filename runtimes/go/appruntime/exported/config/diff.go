@@ -0,0 +1,314 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FieldDiff describes a field whose value differs between two configs.
+type FieldDiff struct {
+	// Path identifies the field using the JSON field names, such as
+	// "sql_databases[orders].password". Elements of slices are
+	// identified by their name or ID where they have one, and by
+	// their index otherwise.
+	Path string
+
+	// Old and New are the JSON-encoded values of the field in each config,
+	// or "" if the field is absent. Secrets are never included: a changed
+	// secret is rendered as "[redacted]" in Old and "[changed]" in New.
+	Old, New string
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.Path, orNone(d.Old), orNone(d.New))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// changed is the placeholder for the new value of a changed secret.
+const changed = "[changed]"
+
+// identityFields are the fields, in order of preference, used to
+// match up slice elements between two configs, so that reordering
+// a slice isn't reported as a change.
+var identityFields = []string{"EncoreName", "Name", "KeyID", "Host", "Method"}
+
+// DiffRuntime reports the fields that differ between a and b,
+// such as to summarize what changes when promoting a config
+// from one environment to another.
+//
+// Like [Runtime.Equal], slices whose order carries no meaning are
+// compared regardless of order. Elements with a name or ID are matched
+// up by it, so an added database is reported as such, rather than as
+// changes to all the databases sorted after it. Unknown fields kept
+// in Extra are ignored.
+func DiffRuntime(a, b *Runtime) []FieldDiff {
+	if a == nil {
+		a = &Runtime{}
+	}
+	if b == nil {
+		b = &Runtime{}
+	}
+	a, b = a.Clone(), b.Clone()
+	for _, cfg := range []*Runtime{a, b} {
+		cfg.canonicalize()
+		cfg.DeployedAt = cfg.DeployedAt.UTC()
+	}
+
+	d := &differ{secrets: make(map[fieldAddr]bool)}
+	for _, cfg := range []*Runtime{a, b} {
+		for _, f := range cfg.secretFields() {
+			v := reflect.ValueOf(f.ptr)
+			d.secrets[fieldAddr{v.Pointer(), v.Type().Elem()}] = true
+		}
+	}
+	d.diffValue("", reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem(), false)
+	return d.diffs
+}
+
+// differ collects the differences between two configs.
+type differ struct {
+	diffs []FieldDiff
+
+	// secrets are the addresses of the secret fields of both configs,
+	// as given by [Runtime.secretFields].
+	secrets map[fieldAddr]bool
+}
+
+// fieldAddr identifies a field by its address. The type tells apart
+// a struct from its first field, which share the same address.
+type fieldAddr struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// isSecret reports whether v is one of the secret fields.
+func (d *differ) isSecret(v reflect.Value) bool {
+	return v.IsValid() && v.CanAddr() && d.secrets[fieldAddr{v.Addr().Pointer(), v.Type()}]
+}
+
+// diffValue appends the differences between a and b to d.diffs.
+// An invalid a or b means the value is absent from that config.
+func (d *differ) diffValue(path string, a, b reflect.Value, secret bool) {
+	// Look through pointers, treating nil as absent.
+	for _, v := range []*reflect.Value{&a, &b} {
+		if v.IsValid() && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				*v = reflect.Value{}
+			} else {
+				*v = v.Elem()
+			}
+		}
+	}
+	if !a.IsValid() && !b.IsValid() {
+		return
+	}
+	var t reflect.Type
+	if a.IsValid() {
+		t = a.Type()
+	} else {
+		t = b.Type()
+	}
+
+	switch {
+	case secret:
+		d.diffSecret(path, a, b)
+
+	case t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) && hasJSONFields(t):
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); isJSONField(f) {
+				fa, fb := field(a, i), field(b, i)
+				d.diffValue(joinPath(path, jsonFieldName(f)), fa, fb, d.isSecret(fa) || d.isSecret(fb))
+			}
+		}
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && identityField(t.Elem()) != "":
+		d.diffKeyed(path, sliceByIdentity(a), sliceByIdentity(b))
+
+	case t.Kind() == reflect.Map:
+		d.diffKeyed(path, mapByKey(a), mapByKey(b))
+
+	default:
+		d.diffLeaf(path, a, b)
+	}
+}
+
+// diffKeyed diffs the elements of a and b that have the same key.
+// The keys of a are visited in order, followed by the keys only in b.
+func (d *differ) diffKeyed(path string, a, b keyedValues) {
+	keys := slices.Clone(a.keys)
+	for _, k := range b.keys {
+		if _, ok := a.values[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		d.diffValue(path+"["+k+"]", a.values[k], b.values[k], false)
+	}
+}
+
+// diffLeaf reports a and b if they differ. A value that's absent on one
+// side is only reported if it's non-zero on the other, so that adding
+// an element isn't reported as a change to each of its unset fields.
+func (d *differ) diffLeaf(path string, a, b reflect.Value) {
+	if a.IsValid() && b.IsValid() && reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return
+	}
+	if !a.IsValid() && b.IsZero() && b.Kind() != reflect.Struct || !b.IsValid() && a.IsZero() && a.Kind() != reflect.Struct {
+		return
+	}
+	d.diffs = append(d.diffs, FieldDiff{Path: path, Old: renderJSON(a), New: renderJSON(b)})
+}
+
+// diffSecret reports a and b if they differ, without revealing them.
+// Unchanged secrets are not reported.
+func (d *differ) diffSecret(path string, a, b reflect.Value) {
+	if isEmptySecret(a) && isEmptySecret(b) {
+		return
+	}
+	if a.IsValid() && b.IsValid() && reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return
+	}
+	fd := FieldDiff{Path: path}
+	if !isEmptySecret(a) {
+		fd.Old = redacted
+	}
+	if !isEmptySecret(b) {
+		fd.New = changed
+	}
+	d.diffs = append(d.diffs, fd)
+}
+
+func isEmptySecret(v reflect.Value) bool {
+	return !v.IsValid() || v.Len() == 0
+}
+
+// renderJSON returns the JSON encoding of v, or "" if v is invalid.
+func renderJSON(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return string(data)
+}
+
+// keyedValues are the elements of a slice or map, by key.
+type keyedValues struct {
+	keys   []string // in order
+	values map[string]reflect.Value
+}
+
+func (kv *keyedValues) add(key string, v reflect.Value) {
+	if kv.values == nil {
+		kv.values = make(map[string]reflect.Value)
+	}
+	if _, ok := kv.values[key]; !ok {
+		kv.keys = append(kv.keys, key)
+	}
+	kv.values[key] = v
+}
+
+// sliceByIdentity returns the elements of the slice s keyed by
+// their identity field, or by their index if it's not unique.
+func sliceByIdentity(s reflect.Value) keyedValues {
+	var kv keyedValues
+	if !s.IsValid() {
+		return kv
+	}
+	name := identityField(s.Type().Elem())
+	for i := 0; i < s.Len(); i++ {
+		elem := reflect.Indirect(s.Index(i))
+		var key string
+		if elem.IsValid() {
+			key = fmt.Sprint(elem.FieldByName(name).Interface())
+		}
+		if _, dup := kv.values[key]; dup || key == "" {
+			return sliceByIndex(s)
+		}
+		kv.add(key, s.Index(i))
+	}
+	return kv
+}
+
+func sliceByIndex(s reflect.Value) keyedValues {
+	var kv keyedValues
+	for i := 0; i < s.Len(); i++ {
+		kv.add(fmt.Sprint(i), s.Index(i))
+	}
+	return kv
+}
+
+// mapByKey returns the elements of the map m, sorted by key.
+func mapByKey(m reflect.Value) keyedValues {
+	var kv keyedValues
+	if !m.IsValid() {
+		return kv
+	}
+	keys := make([]string, 0, m.Len())
+	values := make(map[string]reflect.Value, m.Len())
+	for iter := m.MapRange(); iter.Next(); {
+		k := fmt.Sprint(iter.Key().Interface())
+		keys = append(keys, k)
+		values[k] = iter.Value()
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		kv.add(k, values[k])
+	}
+	return kv
+}
+
+// identityField returns the name of the field identifying
+// values of type t, or "" if it has none.
+func identityField(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+	for _, name := range identityFields {
+		if _, ok := t.FieldByName(name); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// hasJSONFields reports whether the struct type t has any
+// fields that are encoded to JSON.
+func hasJSONFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if isJSONField(t.Field(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isJSONField reports whether f is encoded to JSON.
+func isJSONField(f reflect.StructField) bool {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return f.IsExported() && name != "-"
+}
+
+// field returns the i'th field of the struct v, or an invalid
+// value if v is.
+func field(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	return v.Field(i)
+}
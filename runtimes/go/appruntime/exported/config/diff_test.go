@@ -0,0 +1,90 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffRuntime(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Runtime)
+		want   []FieldDiff
+	}{
+		{
+			name:   "identical",
+			modify: func(cfg *Runtime) {},
+		},
+		{
+			name: "changed_api_base_url",
+			modify: func(cfg *Runtime) {
+				cfg.APIBaseURL = "https://api.example.org"
+			},
+			want: []FieldDiff{{Path: "api_base_url", Old: `"https://api.example.com"`, New: `"https://api.example.org"`}},
+		},
+		{
+			name: "added_database",
+			modify: func(cfg *Runtime) {
+				cfg.SQLDatabases = append([]*SQLDatabase{{
+					EncoreName:   "orders",
+					DatabaseName: "orders",
+					User:         "orders-user",
+					Password:     "orders-password",
+				}}, cfg.SQLDatabases...)
+			},
+			want: []FieldDiff{
+				{Path: "sql_databases[orders].encore_name", New: `"orders"`},
+				{Path: "sql_databases[orders].database_name", New: `"orders"`},
+				{Path: "sql_databases[orders].user", New: `"orders-user"`},
+				{Path: "sql_databases[orders].password", New: "[changed]"},
+			},
+		},
+		{
+			name: "changed_secret",
+			modify: func(cfg *Runtime) {
+				cfg.SQLDatabases[0].Password = "rotated-password"
+			},
+			want: []FieldDiff{{Path: "sql_databases[users].password", Old: "[redacted]", New: "[changed]"}},
+		},
+		{
+			name: "reordered_hosted_services",
+			modify: func(cfg *Runtime) {
+				cfg.HostedServices = []string{"b", "a"}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := fullRuntime(), fullRuntime()
+			a.HostedServices = []string{"a", "b"}
+			b.HostedServices = []string{"a", "b"}
+			tt.modify(b)
+
+			got := DiffRuntime(a, b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			for _, d := range got {
+				for _, s := range []string{"sql-password-secret", "orders-password", "rotated-password"} {
+					if strings.Contains(d.Old+d.New, s) {
+						t.Errorf("diff %v leaks a secret", d)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDiffRuntimeRemoved(t *testing.T) {
+	a, b := fullRuntime(), fullRuntime()
+	b.CORS = nil
+	for _, d := range DiffRuntime(a, b) {
+		if !strings.HasPrefix(d.Path, "cors.") || d.Old == "" || d.New != "" {
+			t.Errorf("unexpected diff %v", d)
+		}
+	}
+	if got := DiffRuntime(a, b); len(got) == 0 {
+		t.Error("got no diffs, want the removed cors fields")
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// DiscoveryTarget returns the base URL of the given service
+// as listed in the service discovery config, and whether it is listed.
+func (r *Runtime) DiscoveryTarget(service string) (string, bool) {
+	svc, ok := r.ServiceDiscovery[service]
+	if !ok {
+		return "", false
+	}
+	return svc.URL, true
+}
+
+// validateServiceDiscovery checks that every service discovery entry
+// has an absolute URL, and that no two entries are for the same service.
+func (r *Runtime) validateServiceDiscovery() []error {
+	keys := make([]string, 0, len(r.ServiceDiscovery))
+	for key := range r.ServiceDiscovery {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var errs []error
+	byName := make(map[string]string, len(keys))
+	for _, key := range keys {
+		svc := r.ServiceDiscovery[key]
+		u, err := url.Parse(svc.URL)
+		switch {
+		case svc.URL == "":
+			errs = append(errs, fmt.Errorf("service discovery %q: missing url", key))
+		case err != nil:
+			errs = append(errs, fmt.Errorf("service discovery %q: invalid url: %w", key, err))
+		case u.Scheme == "" || u.Host == "":
+			errs = append(errs, fmt.Errorf("service discovery %q: url %q must include a scheme and host", key, svc.URL))
+		}
+
+		name := svc.Name
+		if name == "" {
+			name = key
+		}
+		if other, dup := byName[name]; dup {
+			errs = append(errs, fmt.Errorf("service discovery %q: service %q is also listed as %q", key, name, other))
+		}
+		byName[name] = key
+	}
+	return errs
+}
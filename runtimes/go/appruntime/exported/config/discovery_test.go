@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDiscoveryTarget(t *testing.T) {
+	cfg := fullRuntime()
+	if got, ok := cfg.DiscoveryTarget("billing"); !ok || got != "http://billing:8080" {
+		t.Errorf("billing: got %q, %v", got, ok)
+	}
+	if got, ok := cfg.DiscoveryTarget("missing"); ok || got != "" {
+		t.Errorf("missing: got %q, %v", got, ok)
+	}
+}
+
+func TestValidateServiceDiscovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Runtime)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Runtime) {},
+		},
+		{
+			name: "malformed_url",
+			modify: func(cfg *Runtime) {
+				cfg.ServiceDiscovery["billing"] = Service{Name: "billing", URL: "billing:8080"}
+			},
+			wantErr: `service discovery "billing": url "billing:8080" must include a scheme and host`,
+		},
+		{
+			name: "unparseable_url",
+			modify: func(cfg *Runtime) {
+				cfg.ServiceDiscovery["billing"] = Service{Name: "billing", URL: "http://bill ing"}
+			},
+			wantErr: `service discovery "billing": invalid url`,
+		},
+		{
+			name: "missing_url",
+			modify: func(cfg *Runtime) {
+				cfg.ServiceDiscovery["billing"] = Service{Name: "billing"}
+			},
+			wantErr: `service discovery "billing": missing url`,
+		},
+		{
+			name: "duplicate_service",
+			modify: func(cfg *Runtime) {
+				cfg.ServiceDiscovery["payments"] = Service{Name: "billing", URL: "http://payments:8080"}
+			},
+			wantErr: `service discovery "payments": service "billing" is also listed as "billing"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fullRuntime()
+			tt.modify(cfg)
+			err := errors.Join(cfg.validateServiceDiscovery()...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRuntimeDuplicateDiscovery(t *testing.T) {
+	raw := `{"api_base_url":"https://example.com","service_discovery":{` +
+		`"billing":{"name":"billing","url":"http://a:8080"},` +
+		`"billing":{"name":"billing","url":"http://b:8080"}}}`
+	_, err := ParseRuntimeStrict(base64.StdEncoding.EncodeToString([]byte(raw)), "")
+	if err == nil || !strings.Contains(err.Error(), `service discovery "billing": defined more than once`) {
+		t.Errorf("got %v, want a duplicate service discovery error", err)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Duration is a time.Duration that can be unmarshaled from JSON either as
+// a number of nanoseconds or as a string accepted by [time.ParseDuration],
+// such as "30s" or "2m".
+//
+// It is always marshaled as a number of nanoseconds, so configs remain
+// readable by older runtimes.
+type Duration time.Duration
+
+// Std returns d as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	// By convention, unmarshaling null is a no-op.
+	if string(data) == "null" {
+		return nil
+	}
+	if !bytes.HasPrefix(data, []byte(`"`)) {
+		var ns int64
+		if err := json.Unmarshal(data, &ns); err != nil {
+			return err
+		}
+		*d = Duration(ns)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		// Return an *UnmarshalTypeError so that encoding/json
+		// annotates it with the name of the field.
+		return &json.UnmarshalTypeError{
+			Value: fmt.Sprintf("string %q", s),
+			Type:  reflect.TypeOf(d).Elem(),
+		}
+	}
+	*d = Duration(parsed)
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDurationUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    time.Duration
+		wantErr string
+	}{
+		{name: "string", json: `{"shutdown_timeout": "30s"}`, want: 30 * time.Second},
+		{name: "string_compound", json: `{"shutdown_timeout": "2m30s"}`, want: 150 * time.Second},
+		{name: "numeric", json: `{"shutdown_timeout": 5000000000}`, want: 5 * time.Second},
+		{name: "invalid_string", json: `{"shutdown_timeout": "soon"}`, wantErr: `string "soon"`},
+		{name: "invalid_type", json: `{"shutdown_timeout": true}`, wantErr: "bool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Runtime
+			err := json.Unmarshal([]byte(tt.json), &cfg)
+			if tt.wantErr != "" {
+				// The error must be an *UnmarshalTypeError,
+				// so that encoding/json annotates it with the field name.
+				var typeErr *json.UnmarshalTypeError
+				if !errors.As(err, &typeErr) {
+					t.Fatalf("got error %v, want a *json.UnmarshalTypeError", err)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := cfg.ShutdownTimeout.Std(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGracefulShutdownDurationStrings(t *testing.T) {
+	var cfg Runtime
+	data := `{"graceful_shutdown": {"total": "10s", "shutdown_hooks": "3s", "handlers": 2000000000}}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	gs := cfg.GracefulShutdown
+	if gs.Total.Std() != 10*time.Second || gs.ShutdownHooks.Std() != 3*time.Second || gs.Handlers.Std() != 2*time.Second {
+		t.Errorf("got total=%v hooks=%v handlers=%v", gs.Total, gs.ShutdownHooks, gs.Handlers)
+	}
+
+	// Durations are marshaled as numbers for compatibility with older runtimes.
+	out, err := json.Marshal(gs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"total":10000000000,"shutdown_hooks":3000000000,"handlers":2000000000}`; string(out) != want {
+		t.Errorf("got %s, want %s", out, want)
+	}
+}
+
+func TestDurationUnmarshalNull(t *testing.T) {
+	d := Duration(time.Second)
+	if err := json.Unmarshal([]byte("null"), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d != Duration(time.Second) {
+		t.Errorf("got %v, want null to leave the duration unchanged", d)
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/ascii85"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// EncodeRuntime encodes the runtime config in the format
+// expected by [ParseRuntime].
+func EncodeRuntime(cfg *Runtime) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal encore runtime config: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// EncodeRuntimeCompressed is like [EncodeRuntime] but gzips
+// the config before encoding it, to reduce its size.
+func EncodeRuntimeCompressed(cfg *Runtime) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal encore runtime config: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return "", fmt.Errorf("could not compress encore runtime config: %w", err)
+	} else if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("could not compress encore runtime config: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// EncodeRuntimeASCII85 is like [EncodeRuntime] but encodes the config
+// using ascii85, framed by "<~" and "~>", which is more compact than base64.
+func EncodeRuntimeASCII85(cfg *Runtime) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal encore runtime config: %w", err)
+	}
+	buf := make([]byte, ascii85.MaxEncodedLen(len(data)))
+	n := ascii85.Encode(buf, data)
+	return ascii85Prefix + string(buf[:n]) + ascii85Suffix, nil
+}
+
+// EncodeStatic encodes the static config in the format
+// expected by [ParseStatic].
+func EncodeStatic(cfg *Static) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal encore static config: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
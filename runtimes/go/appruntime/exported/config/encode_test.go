@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.encore.dev/platform-sdk/pkg/auth"
+)
+
+// fullRuntime returns a runtime config with every field populated.
+func fullRuntime() *Runtime {
+	dur := func(d time.Duration) *Duration { v := Duration(d); return &v }
+	return &Runtime{
+		SchemaVersion: CurrentSchemaVersion,
+		AppID:         "app-id",
+		AppSlug:       "app-slug",
+		APIBaseURL:    "https://api.example.com",
+		EnvID:         "env-id",
+		EnvName:       "staging",
+		EnvType:       "production",
+		EnvCloud:      "gcp",
+		DeployID:      "deploy-id",
+		DeployedAt:    time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+		TraceEndpoint: "https://trace.example.com",
+		AuthKeys:      []EncoreAuthKey{{KeyID: 1, Data: []byte("auth-key-secret-data")}},
+		CORS: &CORS{
+			Debug:                          true,
+			AllowOriginsWithCredentials:    []string{"https://app.example.com"},
+			AllowOriginsWithoutCredentials: []string{"*"},
+			ExtraAllowedHeaders:            []string{"X-Custom"},
+			ExtraExposedHeaders:            []string{"X-Exposed"},
+			AllowPrivateNetworkAccess:      true,
+		},
+		EncoreCloudAPI: &EncoreCloudAPI{
+			Server:   "https://ec.example.com",
+			AuthKeys: []auth.Key{{KeyID: 2, Data: []byte("ec-key-secret-data")}},
+		},
+		SQLServers: []*SQLServer{{
+			Host:         "db.example.com:5432",
+			ServerCACert: testCertPEM,
+			ClientCert:   testCertPEM,
+			ClientKey:    testKeyPEM,
+		}},
+		SQLDatabases: []*SQLDatabase{{
+			ServerID:       0,
+			EncoreName:     "users",
+			DatabaseName:   "users-db",
+			User:           "users-user",
+			Password:       "sql-password-secret",
+			MinConnections: 1,
+			MaxConnections: 10,
+		}},
+		PubsubProviders: []*PubsubProvider{{GCP: &GCPPubsubProvider{}}},
+		PubsubTopics: map[string]*PubsubTopic{
+			"signups": {
+				EncoreName:   "signups",
+				ProviderID:   0,
+				ProviderName: "signups-topic",
+				Limiter:      &Limiter{TokenBucket: &TokenBucketLimiter{PerSecondRate: 10, BucketSize: 5}},
+				Subscriptions: map[string]*PubsubSubscription{
+					"send-welcome": {
+						ID:           "sub-id",
+						EncoreName:   "send-welcome",
+						ProviderName: "send-welcome-sub",
+						Topic:        "signups",
+						GCP:          &PubsubSubscriptionGCPData{ProjectID: "project", PushServiceAccount: "push@example.com"},
+					},
+				},
+				GCP: &PubsubTopicGCPData{ProjectID: "project"},
+			},
+		},
+		RedisServers: []*RedisServer{{
+			Host:      "redis.example.com:6379",
+			User:      "redis-user",
+			Password:  "redis-password-secret",
+			EnableTLS: true,
+		}},
+		RedisDatabases: []*RedisDatabase{{
+			ServerID:       0,
+			EncoreName:     "cache",
+			Database:       1,
+			MinConnections: 1,
+			MaxConnections: 10,
+			KeyPrefix:      "cache/",
+		}},
+		Metrics: &Metrics{
+			CollectionInterval: time.Minute,
+			Datadog:            &DatadogProvider{Site: "datadoghq.com", APIKey: "datadog-api-key-secret"},
+		},
+		Gateways:       []Gateway{{Name: "api-gateway", Host: "api.example.com", Services: []string{"users"}}},
+		HostedServices: []string{"users"},
+		ServiceDiscovery: map[string]Service{
+			"billing": {Name: "billing", URL: "http://billing:8080", Protocol: Http, ServiceAuth: ServiceAuth{Method: "encore-auth"}},
+		},
+		ServiceAuth:     []ServiceAuth{{Method: "encore-auth"}},
+		ShutdownTimeout: Duration(5 * time.Second),
+		GracefulShutdown: &GracefulShutdownTimings{
+			Total:         dur(10 * time.Second),
+			ShutdownHooks: dur(3 * time.Second),
+			Handlers:      dur(2 * time.Second),
+		},
+		DynamicExperiments: []string{"experiment"},
+	}
+}
+
+func TestEncodeRuntime(t *testing.T) {
+	want := fullRuntime()
+	config, err := EncodeRuntime(want)
+	if err != nil {
+		t.Fatalf("EncodeRuntime: %v", err)
+	}
+	got, err := ParseRuntimeErr(config, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestEncodeStatic(t *testing.T) {
+	want := &Static{
+		EncoreCompiler:  "v1.2.3",
+		AppCommit:       CommitInfo{Revision: "abc", Uncommitted: true},
+		BundledServices: []string{"users"},
+		EmbeddedEnvs:    map[string]string{"FOO": "bar"},
+	}
+	config, err := EncodeStatic(want)
+	if err != nil {
+		t.Fatalf("EncodeStatic: %v", err)
+	}
+	got, err := ParseStaticErr(config)
+	if err != nil {
+		t.Fatalf("ParseStaticErr: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestEncodeRuntimeCompressed(t *testing.T) {
+	want := fullRuntime()
+	config, err := EncodeRuntimeCompressed(want)
+	if err != nil {
+		t.Fatalf("EncodeRuntimeCompressed: %v", err)
+	}
+	got, err := ParseRuntimeErr(config, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	// A truncated gzip stream must be reported rather than silently accepted.
+	data, _ := base64.StdEncoding.DecodeString(config)
+	truncated := base64.StdEncoding.EncodeToString(data[:len(data)/2])
+	if _, err := ParseRuntimeErr(truncated, ""); err == nil {
+		t.Error("truncated gzip: expected an error, got nil")
+	}
+}
+
+func TestEncodeRuntimeASCII85(t *testing.T) {
+	want := fullRuntime()
+	config, err := EncodeRuntimeASCII85(want)
+	if err != nil {
+		t.Fatalf("EncodeRuntimeASCII85: %v", err)
+	}
+	got, err := ParseRuntimeErr(config, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	std, err := EncodeRuntime(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config) >= len(std) {
+		t.Errorf("ascii85 config is %d bytes, want it shorter than the %d bytes of base64", len(config), len(std))
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Valid values for (*Runtime).EnvType.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+	EnvEphemeral   = "ephemeral"
+)
+
+// Valid values for (*Runtime).EnvCloud.
+const (
+	CloudAWS    = "aws"
+	CloudGCP    = "gcp"
+	CloudAzure  = "azure"
+	CloudLocal  = "local"
+	CloudEncore = "encore"
+)
+
+var (
+	envTypes  = []string{EnvDevelopment, EnvProduction, EnvTest, EnvEphemeral}
+	envClouds = []string{CloudAWS, CloudGCP, CloudAzure, CloudLocal, CloudEncore}
+)
+
+// IsValidEnvType reports whether s is a known environment type.
+func IsValidEnvType(s string) bool {
+	return slices.Contains(envTypes, s)
+}
+
+// IsValidEnvCloud reports whether s is a known cloud provider.
+func IsValidEnvCloud(s string) bool {
+	return slices.Contains(envClouds, s)
+}
+
+// IsLocalDev reports whether the app is running for local development,
+// under the local Encore daemon.
+func (r *Runtime) IsLocalDev() bool {
+	return r.EnvType == EnvDevelopment && r.EnvCloud == CloudLocal
+}
+
+// IsEphemeral reports whether the app is running in an ephemeral
+// environment, such as a preview environment for a pull request.
+func (r *Runtime) IsEphemeral() bool {
+	return r.EnvType == EnvEphemeral
+}
+
+// validateEnv checks that the environment type and cloud, if set,
+// are known values.
+func (r *Runtime) validateEnv() []error {
+	var errs []error
+	if r.EnvType != "" && !IsValidEnvType(r.EnvType) {
+		errs = append(errs, fmt.Errorf("env type %q: unknown value (must be one of %s)",
+			r.EnvType, strings.Join(envTypes, ", ")))
+	}
+	if r.EnvCloud != "" && !IsValidEnvCloud(r.EnvCloud) {
+		errs = append(errs, fmt.Errorf("env cloud %q: unknown value (must be one of %s)",
+			r.EnvCloud, strings.Join(envClouds, ", ")))
+	}
+	return errs
+}
+
+// maxDNSLabelLength is the maximum length of a DNS label.
+const maxDNSLabelLength = 63
+
+// validateEnvName checks that the environment name, if set, is a valid
+// DNS label, since it's used to construct per-environment hostnames.
+func (r *Runtime) validateEnvName() error {
+	name := r.EnvName
+	if name == "" {
+		return nil
+	}
+	switch {
+	case len(name) > maxDNSLabelLength:
+		return fmt.Errorf("env name %q: longer than %d characters", name, maxDNSLabelLength)
+	case name[0] == '-' || name[len(name)-1] == '-':
+		return fmt.Errorf("env name %q: must not start or end with a hyphen", name)
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return fmt.Errorf("env name %q: invalid character %q (must be lowercase letters, digits, or hyphens)", name, c)
+		}
+	}
+	return nil
+}
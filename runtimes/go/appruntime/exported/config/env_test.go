@@ -0,0 +1,169 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsValidEnv(t *testing.T) {
+	for _, s := range []string{EnvDevelopment, EnvProduction, EnvTest, EnvEphemeral} {
+		if !IsValidEnvType(s) {
+			t.Errorf("IsValidEnvType(%q) = false, want true", s)
+		}
+	}
+	for _, s := range []string{CloudAWS, CloudGCP, CloudAzure, CloudLocal, CloudEncore} {
+		if !IsValidEnvCloud(s) {
+			t.Errorf("IsValidEnvCloud(%q) = false, want true", s)
+		}
+	}
+	for _, s := range []string{"", "prod", "Production", "dev"} {
+		if IsValidEnvType(s) {
+			t.Errorf("IsValidEnvType(%q) = true, want false", s)
+		}
+	}
+	for _, s := range []string{"", "amazon", "GCP"} {
+		if IsValidEnvCloud(s) {
+			t.Errorf("IsValidEnvCloud(%q) = true, want false", s)
+		}
+	}
+}
+
+func TestIsLocalDevEphemeral(t *testing.T) {
+	tests := []struct {
+		envType, envCloud string
+		wantLocal         bool
+		wantEphemeral     bool
+	}{
+		{EnvDevelopment, CloudLocal, true, false},
+		{EnvDevelopment, CloudGCP, false, false},
+		{EnvTest, CloudLocal, false, false},
+		{EnvEphemeral, CloudEncore, false, true},
+		{EnvProduction, CloudAWS, false, false},
+		{"", "", false, false},
+	}
+	for _, tt := range tests {
+		cfg := &Runtime{EnvType: tt.envType, EnvCloud: tt.envCloud}
+		if got := cfg.IsLocalDev(); got != tt.wantLocal {
+			t.Errorf("%s/%s: IsLocalDev() = %v, want %v", tt.envType, tt.envCloud, got, tt.wantLocal)
+		}
+		if got := cfg.IsEphemeral(); got != tt.wantEphemeral {
+			t.Errorf("%s/%s: IsEphemeral() = %v, want %v", tt.envType, tt.envCloud, got, tt.wantEphemeral)
+		}
+	}
+}
+
+func TestParseRuntimeValidatedEnv(t *testing.T) {
+	cfg := fullRuntime()
+	cfg.EnvType = "prod"
+	cfg.EnvCloud = "amazon"
+	config, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Parsing is lenient unless validation is requested.
+	if _, err := ParseRuntimeErr(config, ""); err != nil {
+		t.Errorf("ParseRuntimeErr: unexpected error: %v", err)
+	}
+
+	_, err = ParseRuntimeValidated(config, "")
+	if err == nil {
+		t.Fatal("ParseRuntimeValidated: expected an error, got nil")
+	}
+	for _, want := range []string{
+		`env type "prod": unknown value (must be one of development, production, test, ephemeral)`,
+		`env cloud "amazon": unknown value (must be one of aws, gcp, azure, local, encore)`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestValidateEnvName(t *testing.T) {
+	tests := []struct {
+		name    string
+		envName string
+		wantErr string
+	}{
+		{name: "valid", envName: "staging-2"},
+		{name: "empty", envName: ""},
+		{name: "max_length", envName: strings.Repeat("a", 63)},
+		{name: "underscore", envName: "my_env", wantErr: `env name "my_env": invalid character '_'`},
+		{name: "uppercase", envName: "Prod", wantErr: `env name "Prod": invalid character 'P'`},
+		{name: "leading_hyphen", envName: "-prod", wantErr: `env name "-prod": must not start or end with a hyphen`},
+		{name: "trailing_hyphen", envName: "prod-", wantErr: `env name "prod-": must not start or end with a hyphen`},
+		{name: "too_long", envName: strings.Repeat("a", 64), wantErr: "longer than 63 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Runtime{EnvName: tt.envName}
+			err := cfg.validateEnvName()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The name is only checked when validating.
+	cfg := fullRuntime()
+	cfg.EnvName = "my_env"
+	config, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseRuntimeErr(config, ""); err != nil {
+		t.Errorf("ParseRuntimeErr: unexpected error: %v", err)
+	}
+	if _, err := ParseRuntimeValidated(config, ""); err == nil || !strings.Contains(err.Error(), `env name "my_env"`) {
+		t.Errorf("ParseRuntimeValidated: got %v, want an env name error", err)
+	}
+}
+
+func TestWithCloudFallback(t *testing.T) {
+	unknown := encodeJSON(t, map[string]any{"api_base_url": "https://example.com", "env_cloud": "newcloud"})
+	known := encodeJSON(t, map[string]any{"api_base_url": "https://example.com", "env_cloud": "aws"})
+
+	var warned []string
+	warn := WithDeprecationHandler(func(field string) { warned = append(warned, field) })
+
+	// The fallback is applied, with a warning.
+	cfg, err := ParseRuntimeOptions(unknown, WithCloudFallback(CloudLocal), warn)
+	if err != nil {
+		t.Fatalf("fallback applied: %v", err)
+	}
+	if cfg.EnvCloud != CloudLocal {
+		t.Errorf("fallback applied: got env cloud %q, want %q", cfg.EnvCloud, CloudLocal)
+	}
+	if len(warned) != 1 || warned[0] != "env_cloud" {
+		t.Errorf("fallback applied: got warnings %q, want [env_cloud]", warned)
+	}
+
+	// A known cloud is left alone.
+	warned = nil
+	cfg, err = ParseRuntimeOptions(known, WithCloudFallback(CloudLocal), warn)
+	if err != nil {
+		t.Fatalf("fallback not needed: %v", err)
+	}
+	if cfg.EnvCloud != CloudAWS || len(warned) != 0 {
+		t.Errorf("fallback not needed: got env cloud %q, warnings %q", cfg.EnvCloud, warned)
+	}
+
+	// Without a fallback, an unknown cloud is kept, or rejected in strict mode.
+	if cfg, err := ParseRuntimeOptions(unknown); err != nil || cfg.EnvCloud != "newcloud" {
+		t.Errorf("lenient: got %+v, %v", cfg, err)
+	}
+	_, err = ParseRuntimeOptions(unknown, WithStrict())
+	if err == nil || !strings.Contains(err.Error(), `env cloud "newcloud": unknown value`) {
+		t.Errorf("strict: got %v, want an unknown env cloud error", err)
+	}
+	if _, err := ParseRuntimeOptions(unknown, WithStrict(), WithCloudFallback(CloudLocal)); err != nil {
+		t.Errorf("strict with fallback: unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"net"
+	"strings"
+)
+
+// EnvVars flattens the config into environment variables, for tools
+// that read discrete variables rather than the encoded config.
+//
+// The variables describing the app and environment are
+//
+//	ENCORE_APP_ID, ENCORE_APP_SLUG, ENCORE_API_BASE_URL,
+//	ENCORE_ENV_NAME, ENCORE_ENV_TYPE, ENCORE_ENV_CLOUD, ENCORE_DEPLOY_ID
+//
+// and each SQL database is described by
+//
+//	ENCORE_SQLDB_<NAME>_HOST      the server hostname, or unix socket path
+//	ENCORE_SQLDB_<NAME>_PORT      the server port, if the host has one
+//	ENCORE_SQLDB_<NAME>_NAME      the database name on the server
+//	ENCORE_SQLDB_<NAME>_USER
+//	ENCORE_SQLDB_<NAME>_PASSWORD
+//	ENCORE_SQLDB_<NAME>_URL       see [Runtime.SQLConnString]
+//
+// where <NAME> is the Encore name of the database in upper case, with
+// characters other than letters and digits replaced by underscores.
+// Variables whose value is empty are omitted.
+//
+// The result includes secrets, such as database passwords,
+// so it's up to the caller to keep it out of logs.
+func (r *Runtime) EnvVars() map[string]string {
+	vars := make(map[string]string)
+	set := func(name, value string) {
+		if value != "" {
+			vars[name] = value
+		}
+	}
+
+	set("ENCORE_APP_ID", r.AppID)
+	set("ENCORE_APP_SLUG", r.AppSlug)
+	set("ENCORE_API_BASE_URL", r.APIBaseURL)
+	set("ENCORE_ENV_NAME", r.EnvName)
+	set("ENCORE_ENV_TYPE", r.EnvType)
+	set("ENCORE_ENV_CLOUD", r.EnvCloud)
+	set("ENCORE_DEPLOY_ID", r.DeployID)
+
+	for _, db := range r.SQLDatabases {
+		prefix := "ENCORE_SQLDB_" + envVarName(db.EncoreName) + "_"
+		if srv, ok := r.SQLServer(db.ServerID); ok {
+			host, port := srv.Host, ""
+			if !strings.HasPrefix(host, "/") {
+				if h, p, err := net.SplitHostPort(host); err == nil {
+					host, port = h, p
+				}
+			}
+			set(prefix+"HOST", host)
+			set(prefix+"PORT", port)
+		}
+		set(prefix+"NAME", db.DatabaseName)
+		set(prefix+"USER", db.User)
+		set(prefix+"PASSWORD", db.Password)
+		if connStr, err := r.SQLConnString(db.EncoreName); err == nil {
+			set(prefix+"URL", connStr)
+		}
+	}
+	return vars
+}
+
+// envVarName returns name in upper case, with characters other
+// than ASCII letters and digits replaced by underscores.
+func envVarName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
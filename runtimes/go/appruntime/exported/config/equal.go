@@ -0,0 +1,66 @@
+package config
+
+import "reflect"
+
+// Equal reports whether r and other describe the same config.
+//
+// Slices whose order carries no meaning, such as the SQL and Redis
+// servers and databases, are compared as sets. References to servers
+// are compared by the server they point to rather than by index.
+// A nil slice or map is equal to an empty one.
+func (r *Runtime) Equal(other *Runtime) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+
+	a, b := r.Clone(), other.Clone()
+	for _, cfg := range []*Runtime{a, b} {
+		cfg.canonicalize()
+		cfg.DeployedAt = cfg.DeployedAt.UTC()
+		nilEmpty(reflect.ValueOf(cfg).Elem())
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// nilEmpty sets all empty slices and maps reachable from v to nil,
+// so that reflect.DeepEqual treats them as equal.
+func nilEmpty(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			nilEmpty(v.Elem())
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				nilEmpty(v.Field(i))
+			}
+		}
+
+	case reflect.Slice:
+		if v.Len() == 0 {
+			if !v.IsNil() && v.CanSet() {
+				v.SetZero()
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			nilEmpty(v.Index(i))
+		}
+
+	case reflect.Map:
+		if v.Len() == 0 {
+			if !v.IsNil() && v.CanSet() {
+				v.SetZero()
+			}
+			return
+		}
+		// Map values aren't addressable, so only values
+		// that are pointers can be normalized.
+		iter := v.MapRange()
+		for iter.Next() {
+			nilEmpty(iter.Value())
+		}
+	}
+}
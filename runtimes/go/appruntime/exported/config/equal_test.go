@@ -0,0 +1,71 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	if !fullRuntime().Equal(fullRuntime()) {
+		t.Fatal("identical configs are not equal")
+	}
+
+	// Reordering the servers and databases, and renumbering the server
+	// references to match, doesn't change the config.
+	a := fullRuntime()
+	a.SQLServers = append(a.SQLServers, &SQLServer{Host: "replica.example.com:5432"})
+	a.SQLDatabases = append(a.SQLDatabases, &SQLDatabase{ServerID: 1, EncoreName: "orders"})
+	a.RedisServers = append(a.RedisServers, &RedisServer{Host: "cache2.example.com:6379"})
+	a.RedisDatabases = append(a.RedisDatabases, &RedisDatabase{ServerID: 1, EncoreName: "sessions"})
+
+	b := a.Clone()
+	slices.Reverse(b.SQLServers)
+	slices.Reverse(b.SQLDatabases)
+	for _, db := range b.SQLDatabases {
+		db.ServerID = 1 - db.ServerID
+	}
+	slices.Reverse(b.RedisServers)
+	slices.Reverse(b.RedisDatabases)
+	for _, db := range b.RedisDatabases {
+		db.ServerID = 1 - db.ServerID
+	}
+	if !a.Equal(b) {
+		t.Error("reordered configs are not equal")
+	}
+
+	// Moving a database to a different server is a real change.
+	b.SQLDatabases[0].ServerID = 1 - b.SQLDatabases[0].ServerID
+	if a.Equal(b) {
+		t.Error("configs with different database servers are equal")
+	}
+
+	// Nil and empty slices and maps are equivalent.
+	a, b = fullRuntime(), fullRuntime()
+	a.HostedServices, b.HostedServices = nil, []string{}
+	a.DynamicExperiments, b.DynamicExperiments = nil, []string{}
+	a.PubsubTopics["signups"].Subscriptions = nil
+	b.PubsubTopics["signups"].Subscriptions = map[string]*PubsubSubscription{}
+	if !a.Equal(b) {
+		t.Error("nil and empty slices are not equal")
+	}
+
+	b.AppID = "other"
+	if a.Equal(b) {
+		t.Error("configs with different app ids are equal")
+	}
+
+	if !(*Runtime)(nil).Equal(nil) || fullRuntime().Equal(nil) {
+		t.Error("nil configs compare incorrectly")
+	}
+
+	// Equal doesn't modify either config.
+	a, b = fullRuntime(), fullRuntime()
+	a.HostedServices = []string{"b", "a"}
+	b.HostedServices = []string{"a", "b"}
+	if !a.Equal(b) {
+		t.Error("reordered hosted services are not equal")
+	}
+	if a.HostedServices[0] != "b" {
+		t.Error("Equal modified the receiver")
+	}
+}
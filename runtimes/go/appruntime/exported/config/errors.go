@@ -0,0 +1,27 @@
+package config
+
+// Stage describes the stage of config parsing at which an error occurred.
+type Stage string
+
+const (
+	StageDecode    Stage = "decode"    // decoding the config string into bytes
+	StageDecrypt   Stage = "decrypt"   // decrypting the decoded config
+	StageUnmarshal Stage = "unmarshal" // unmarshaling the JSON config
+	StageExpand    Stage = "expand"    // expanding environment variable references
+	StageResolve   Stage = "resolve"   // resolving secret references
+	StageValidate  Stage = "validate"  // validating the unmarshaled config
+)
+
+// ParseError is the error returned when parsing a config fails.
+type ParseError struct {
+	Stage Stage // the stage at which parsing failed
+	Err   error // the underlying error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestParseErrorStage(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   Stage
+	}{
+		{"empty", "", StageDecode},
+		{"bad_base64", "not base64!", StageDecode},
+		{"bad_json", base64.StdEncoding.EncodeToString([]byte("{not json")), StageUnmarshal},
+		{"bad_url", base64.StdEncoding.EncodeToString([]byte(`{"api_base_url": "://"}`)), StageValidate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRuntimeErr(tt.config, "")
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("got %v, want a *ParseError", err)
+			}
+			if perr.Stage != tt.want {
+				t.Errorf("got stage %q, want %q", perr.Stage, tt.want)
+			}
+		})
+	}
+}
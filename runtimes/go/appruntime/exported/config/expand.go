@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// expandEnv expands ${VAR} and $VAR references in the hosts and
+// credentials of the config's SQL and Redis servers using lookup.
+//
+// It reports all variables that lookup could not resolve.
+func (r *Runtime) expandEnv(lookup func(string) (string, bool)) error {
+	var missing []string
+	expand := func(s *string) {
+		*s = os.Expand(*s, func(name string) string {
+			val, ok := lookup(name)
+			if !ok {
+				missing = append(missing, name)
+			}
+			return val
+		})
+	}
+
+	for _, srv := range r.SQLServers {
+		expand(&srv.Host)
+	}
+	for _, db := range r.SQLDatabases {
+		expand(&db.User)
+		expand(&db.Password)
+	}
+	for _, srv := range r.RedisServers {
+		expand(&srv.Host)
+		expand(&srv.User)
+		expand(&srv.Password)
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		missing = slices.Compact(missing)
+		return fmt.Errorf("undefined environment variables: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// mapSQLHosts replaces the host of each SQL server with the one in
+// mapping for its ID, or failing that for its host. See [WithSQLHostMapping].
+func (r *Runtime) mapSQLHosts(mapping map[string]string) {
+	for id, srv := range r.SQLServers {
+		if host, ok := mapping[strconv.Itoa(id)]; ok {
+			srv.Host = host
+		} else if host, ok := mapping[srv.Host]; ok {
+			srv.Host = host
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseRuntimeExpand(t *testing.T) {
+	cfg := fullRuntime()
+	cfg.SQLServers[0].Host = "${DB_HOST}:5432"
+	cfg.SQLDatabases[0].Password = "$DB_PASSWORD"
+	cfg.RedisServers[0].Password = "${REDIS_PASSWORD}"
+	config, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"DB_HOST":        "db.internal",
+		"DB_PASSWORD":    "db-pass",
+		"REDIS_PASSWORD": "redis-pass",
+	}
+	lookup := func(name string) (string, bool) {
+		val, ok := env[name]
+		return val, ok
+	}
+
+	got, err := ParseRuntimeExpand(config, "", lookup)
+	if err != nil {
+		t.Fatalf("ParseRuntimeExpand: %v", err)
+	}
+	if h := got.SQLServers[0].Host; h != "db.internal:5432" {
+		t.Errorf("sql server host: got %q, want %q", h, "db.internal:5432")
+	}
+	if p := got.SQLDatabases[0].Password; p != "db-pass" {
+		t.Errorf("sql password: got %q, want %q", p, "db-pass")
+	}
+	if p := got.RedisServers[0].Password; p != "redis-pass" {
+		t.Errorf("redis password: got %q, want %q", p, "redis-pass")
+	}
+
+	// Without expansion the references are left as-is.
+	got, err = ParseRuntimeErr(config, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+	if h := got.SQLServers[0].Host; h != "${DB_HOST}:5432" {
+		t.Errorf("unexpanded sql server host: got %q", h)
+	}
+
+	// Missing variables are all reported.
+	delete(env, "DB_HOST")
+	delete(env, "REDIS_PASSWORD")
+	_, err = ParseRuntimeExpand(config, "", lookup)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Stage != StageExpand {
+		t.Fatalf("missing variables: got %v, want a ParseError at stage %q", err, StageExpand)
+	}
+	if !strings.Contains(err.Error(), "DB_HOST, REDIS_PASSWORD") {
+		t.Errorf("error %q does not list the missing variables", err)
+	}
+}
+
+func TestWithSQLHostMapping(t *testing.T) {
+	cfg := fullRuntime()
+	cfg.SQLServers = append(cfg.SQLServers, &SQLServer{Host: "replica.example.com:5432"})
+	config, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		want    []string
+	}{
+		{
+			name:    "none",
+			mapping: nil,
+			want:    []string{"db.example.com:5432", "replica.example.com:5432"},
+		},
+		{
+			name: "full",
+			mapping: map[string]string{
+				"0":                        "localhost:15432",
+				"replica.example.com:5432": "localhost:15433",
+			},
+			want: []string{"localhost:15432", "localhost:15433"},
+		},
+		{
+			name:    "partial",
+			mapping: map[string]string{"1": "localhost:15433", "other.example.com:5432": "localhost:1"},
+			want:    []string{"db.example.com:5432", "localhost:15433"},
+		},
+		{
+			name: "id_before_host",
+			mapping: map[string]string{
+				"0":                   "localhost:15432",
+				"db.example.com:5432": "localhost:1",
+			},
+			want: []string{"localhost:15432", "replica.example.com:5432"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRuntimeOptions(config, WithSQLHostMapping(tt.mapping))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var hosts []string
+			for _, srv := range got.SQLServers {
+				hosts = append(hosts, srv.Host)
+			}
+			if strings.Join(hosts, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("hosts: got %q, want %q", hosts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// runtimeJSON has the same fields as Runtime, but without its custom
+// JSON methods, so it can be used to get the default encoding.
+type runtimeJSON Runtime
+
+// extraFields returns the top-level fields of the JSON-encoded config
+// in data that aren't fields of Runtime, or nil if there are none.
+// It decodes the whole config again, so it's only used when parsing
+// with [WithExtraFields].
+func extraFields(data []byte) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	var extra map[string]json.RawMessage
+	for name, value := range fields {
+		if !isRuntimeField(name) {
+			if extra == nil {
+				extra = make(map[string]json.RawMessage)
+			}
+			extra[name] = value
+		}
+	}
+	return extra, nil
+}
+
+// MarshalJSON implements json.Marshaler.
+// It encodes the known fields as usual, followed by the fields in
+// r.Extra sorted by name. Extra fields that clash with a known field
+// are skipped, so that they can't override it.
+func (r Runtime) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal((*runtimeJSON)(&r))
+	if err != nil || len(r.Extra) == 0 {
+		return data, err
+	}
+
+	names := make([]string, 0, len(r.Extra))
+	for name := range r.Extra {
+		if !isRuntimeField(name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1]) // strip the closing brace
+	for _, name := range names {
+		value := r.Extra[name]
+		if !json.Valid(value) {
+			return nil, fmt.Errorf("extra field %q: invalid JSON value", name)
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// runtimeFields returns the JSON names of the fields of Runtime.
+var runtimeFields = sync.OnceValue(func() []string {
+	t := reflect.TypeOf(Runtime{})
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.IsExported() && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+})
+
+// isRuntimeField reports whether name is the JSON name of a field of Runtime.
+// Like encoding/json, it matches names case-insensitively.
+func isRuntimeField(name string) bool {
+	for _, field := range runtimeFields() {
+		if strings.EqualFold(field, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExtraRoundTrip(t *testing.T) {
+	const future = `{"mode":"fast","limits":[1,2,3],"nested":{"on":true}}`
+	raw := `{"app_id":"app","api_base_url":"https://example.com","future_field":` + future + `,"another":"x"}`
+
+	config := base64.StdEncoding.EncodeToString([]byte(raw))
+
+	// Unknown fields are only kept when asked for.
+	cfg, err := ParseRuntimeErr(config, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+	if cfg.Extra != nil {
+		t.Errorf("Extra: got %v without WithExtraFields, want nil", cfg.Extra)
+	}
+
+	cfg, err = ParseRuntimeOptions(config, WithExtraFields())
+	if err != nil {
+		t.Fatalf("ParseRuntimeOptions: %v", err)
+	}
+	if got := string(cfg.Extra["future_field"]); got != future {
+		t.Errorf("Extra[future_field]: got %s, want %s", got, future)
+	}
+	if _, ok := cfg.Extra["app_id"]; ok {
+		t.Error("Extra contains the known field app_id")
+	}
+
+	cfg.AppSlug = "tweaked"
+	encoded, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatalf("EncodeRuntime: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(fields["future_field"]); got != future {
+		t.Errorf("re-encoded future_field: got %s, want %s", got, future)
+	}
+	if got := string(fields["another"]); got != `"x"` {
+		t.Errorf("re-encoded another: got %s, want %q", got, `"x"`)
+	}
+	if got := string(fields["app_slug"]); got != `"tweaked"` {
+		t.Errorf("re-encoded app_slug: got %s", got)
+	}
+
+	// Strict parsing still rejects the unknown fields.
+	if _, err := ParseRuntimeOptions(config, WithExtraFields(), WithStrict()); err == nil {
+		t.Error("strict: expected an error, got nil")
+	}
+}
+
+func TestExtraMarshalJSON(t *testing.T) {
+	cfg := &Runtime{
+		AppID: "app",
+		Extra: map[string]json.RawMessage{
+			"b_field": json.RawMessage(`2`),
+			"a_field": json.RawMessage(`1`),
+			"APP_ID":  json.RawMessage(`"override"`),
+		},
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	got := string(data)
+	if !strings.HasSuffix(got, `,"a_field":1,"b_field":2}`) {
+		t.Errorf("got %s, want the extra fields appended in order", got)
+	}
+	if strings.Contains(got, "override") {
+		t.Errorf("got %s, want the clashing extra field skipped", got)
+	}
+
+	cfg.Extra = map[string]json.RawMessage{"bad": json.RawMessage(`{`)}
+	if _, err := json.Marshal(cfg); err == nil || !strings.Contains(err.Error(), `extra field "bad"`) {
+		t.Errorf("invalid extra: got %v", err)
+	}
+}
+
+func TestExtraClone(t *testing.T) {
+	cfg := &Runtime{Extra: map[string]json.RawMessage{"x": json.RawMessage(`[1]`)}}
+	c := cfg.Clone()
+	c.Extra["x"][1] = '2'
+	if got := string(cfg.Extra["x"]); got != `[1]` {
+		t.Errorf("modifying the clone changed the original: %s", got)
+	}
+	if r := cfg.Redacted(); r.Extra != nil {
+		t.Errorf("Redacted kept extra fields: %v", r.Extra)
+	}
+}
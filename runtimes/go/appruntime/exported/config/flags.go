@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// FlagError describes a feature flag whose value
+// is malformed for the type it was read as.
+type FlagError struct {
+	Name  string // the flag name
+	Value string // the malformed value
+	Type  string // the type the value was parsed as, like "bool" or "int"
+	Err   error  // the underlying parse error
+}
+
+func (e *FlagError) Error() string {
+	return fmt.Sprintf("flag %q: invalid %s value %q: %v", e.Name, e.Type, e.Value, e.Err)
+}
+
+func (e *FlagError) Unwrap() error { return e.Err }
+
+// FlagString returns the value of the flag with the given name,
+// and whether it is set.
+func (r *Runtime) FlagString(name string) (string, bool) {
+	v, ok := r.Flags[name]
+	return v, ok
+}
+
+// FlagBool returns the boolean value of the flag with the given name,
+// and whether it is set. It accepts the values accepted by [strconv.ParseBool].
+//
+// If the flag is set to a malformed value it returns false, true.
+// Use [Runtime.ParseFlagBool] to get the [*FlagError] describing the problem.
+func (r *Runtime) FlagBool(name string) (bool, bool) {
+	v, ok := r.Flags[name]
+	if !ok {
+		return false, false
+	}
+	b, _ := parseFlagBool(name, v)
+	return b, true
+}
+
+// FlagInt returns the integer value of the flag with the given name,
+// and whether it is set.
+//
+// If the flag is set to a malformed value it returns 0, true.
+// Use [Runtime.ParseFlagInt] to get the [*FlagError] describing the problem.
+func (r *Runtime) FlagInt(name string) (int, bool) {
+	v, ok := r.Flags[name]
+	if !ok {
+		return 0, false
+	}
+	n, _ := parseFlagInt(name, v)
+	return n, true
+}
+
+// ParseFlagBool is like [Runtime.FlagBool] but returns a [*FlagError]
+// if the flag is set to a malformed value. It returns false, nil if
+// the flag is not set.
+func (r *Runtime) ParseFlagBool(name string) (bool, error) {
+	v, ok := r.Flags[name]
+	if !ok {
+		return false, nil
+	}
+	return parseFlagBool(name, v)
+}
+
+// ParseFlagInt is like [Runtime.FlagInt] but returns a [*FlagError]
+// if the flag is set to a malformed value. It returns 0, nil if
+// the flag is not set.
+func (r *Runtime) ParseFlagInt(name string) (int, error) {
+	v, ok := r.Flags[name]
+	if !ok {
+		return 0, nil
+	}
+	return parseFlagInt(name, v)
+}
+
+func parseFlagBool(name, value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &FlagError{Name: name, Value: value, Type: "bool", Err: err}
+	}
+	return b, nil
+}
+
+func parseFlagInt(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &FlagError{Name: name, Value: value, Type: "int", Err: err}
+	}
+	return n, nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	cfg := &Runtime{Flags: map[string]string{
+		"enabled":   "true",
+		"disabled":  "0",
+		"bad_bool":  "yes please",
+		"workers":   "8",
+		"negative":  "-3",
+		"bad_int":   "eight",
+		"overflow":  "99999999999999999999",
+		"greeting":  "hello",
+		"empty_str": "",
+	}}
+
+	t.Run("bool", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			want        bool
+			wantPresent bool
+			wantErr     bool
+		}{
+			{name: "enabled", want: true, wantPresent: true},
+			{name: "disabled", want: false, wantPresent: true},
+			{name: "bad_bool", want: false, wantPresent: true, wantErr: true},
+			{name: "missing", want: false, wantPresent: false},
+		}
+		for _, tt := range tests {
+			got, present := cfg.FlagBool(tt.name)
+			if got != tt.want || present != tt.wantPresent {
+				t.Errorf("FlagBool(%q) = %v, %v, want %v, %v", tt.name, got, present, tt.want, tt.wantPresent)
+			}
+			_, err := cfg.ParseFlagBool(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFlagBool(%q): got err %v, want error: %v", tt.name, err, tt.wantErr)
+			}
+		}
+	})
+
+	t.Run("int", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			want        int
+			wantPresent bool
+			wantErr     error
+		}{
+			{name: "workers", want: 8, wantPresent: true},
+			{name: "negative", want: -3, wantPresent: true},
+			{name: "bad_int", want: 0, wantPresent: true, wantErr: strconv.ErrSyntax},
+			{name: "overflow", want: 0, wantPresent: true, wantErr: strconv.ErrRange},
+			{name: "missing", want: 0, wantPresent: false},
+		}
+		for _, tt := range tests {
+			got, present := cfg.FlagInt(tt.name)
+			if got != tt.want || present != tt.wantPresent {
+				t.Errorf("FlagInt(%q) = %v, %v, want %v, %v", tt.name, got, present, tt.want, tt.wantPresent)
+			}
+			_, err := cfg.ParseFlagInt(tt.name)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseFlagInt(%q): got err %v, want %v", tt.name, err, tt.wantErr)
+			}
+		}
+	})
+
+	t.Run("string", func(t *testing.T) {
+		if got, ok := cfg.FlagString("greeting"); got != "hello" || !ok {
+			t.Errorf("FlagString(greeting) = %q, %v", got, ok)
+		}
+		if got, ok := cfg.FlagString("empty_str"); got != "" || !ok {
+			t.Errorf("FlagString(empty_str) = %q, %v", got, ok)
+		}
+		if got, ok := cfg.FlagString("missing"); got != "" || ok {
+			t.Errorf("FlagString(missing) = %q, %v", got, ok)
+		}
+	})
+
+	t.Run("flag_error", func(t *testing.T) {
+		_, err := cfg.ParseFlagInt("bad_int")
+		var flagErr *FlagError
+		if !errors.As(err, &flagErr) {
+			t.Fatalf("got %v, want a *FlagError", err)
+		}
+		if flagErr.Name != "bad_int" || flagErr.Value != "eight" || flagErr.Type != "int" {
+			t.Errorf("got %+v", flagErr)
+		}
+		want := `flag "bad_int": invalid int value "eight": strconv.Atoi: parsing "eight": invalid syntax`
+		if err.Error() != want {
+			t.Errorf("got message %q, want %q", err.Error(), want)
+		}
+	})
+
+	// A nil map has no flags.
+	var empty Runtime
+	if _, ok := empty.FlagBool("enabled"); ok {
+		t.Error("FlagBool on empty config: got present")
+	}
+}
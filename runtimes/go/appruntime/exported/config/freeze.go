@@ -0,0 +1,23 @@
+package config
+
+import "errors"
+
+// ErrFrozen is returned by the mutation helpers, such as [Runtime.Merge],
+// when called on a runtime config that has been frozen using [Runtime.Freeze].
+var ErrFrozen = errors.New("runtime config is frozen")
+
+// Freeze marks the runtime config as immutable, making the mutation
+// helpers [Runtime.SetSecret], [Runtime.Merge] and [Runtime.ApplyDefaults]
+// return [ErrFrozen] instead of modifying it. It's meant to be called
+// once startup is complete and the config is shared.
+//
+// Freezing can't be undone, but [Runtime.Clone] returns a copy
+// that isn't frozen. Fields can still be assigned to directly.
+func (r *Runtime) Freeze() {
+	r.frozen = true
+}
+
+// Frozen reports whether the runtime config has been frozen using [Runtime.Freeze].
+func (r *Runtime) Frozen() bool {
+	return r.frozen
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	cfg := &Runtime{SQLDatabases: []*SQLDatabase{{EncoreName: "users", Password: "secret"}}}
+	if cfg.Frozen() {
+		t.Fatal("new config is frozen")
+	}
+	cfg.Freeze()
+	if !cfg.Frozen() {
+		t.Fatal("Freeze did not freeze the config")
+	}
+
+	if err := cfg.SetSecret("sql/users/password", "changed"); !errors.Is(err, ErrFrozen) {
+		t.Errorf("SetSecret: got %v, want %v", err, ErrFrozen)
+	}
+	if err := cfg.Merge(&Runtime{AppSlug: "changed"}); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Merge: got %v, want %v", err, ErrFrozen)
+	}
+	if err := cfg.ApplyDefaults(); !errors.Is(err, ErrFrozen) {
+		t.Errorf("ApplyDefaults: got %v, want %v", err, ErrFrozen)
+	}
+	if cfg.SQLDatabases[0].Password != "secret" || cfg.AppSlug != "" || cfg.EnvType != "" || cfg.GracefulShutdown != nil {
+		t.Errorf("a mutator modified the frozen config: %+v", cfg)
+	}
+
+	// A clone can be modified again, and compares equal to the original.
+	c := cfg.Clone()
+	if c.Frozen() {
+		t.Error("clone is frozen")
+	}
+	if !c.Equal(cfg) {
+		t.Error("clone is not equal to the frozen original")
+	}
+	if err := c.SetSecret("sql/users/password", "changed"); err != nil {
+		t.Errorf("SetSecret on clone: %v", err)
+	}
+}
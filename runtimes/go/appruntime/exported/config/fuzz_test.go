@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+// jsonSeeds are runtime configs, in JSON, for seeding the fuzz corpus.
+var jsonSeeds = []string{
+	`{"app_id": "app", "api_base_url": "https://example.com"}`,
+	`{"api_base_url": "https://example.com", "shutdown_timeout": "5s"}`,
+	`{"api_base_url": "https://example.com", "graceful_shutdown": {"total": 1000, "handlers": "1s"}}`,
+	`{"api_base_url": "https://example.com", "pubsub_providers": [{}], "pubsub_topics": {"a": {}, "a": {}}}`,
+	`{"api_base_url": "https://example.com", "deploy_time": "2024-01-02T03:04:05+01:00"}`,
+	`{"api_base_url": "https://example.com", "auth_keys": [{"kid": 1, "data": "c2VjcmV0"}]}`,
+	`{"api_base_url": "://"}`,
+	`{not json`,
+}
+
+// addSeeds adds the configs used by the other tests to the fuzz corpus.
+func addSeeds(f *testing.F) {
+	f.Helper()
+	for _, encode := range []func(*Runtime) (string, error){
+		EncodeRuntime,
+		EncodeRuntimeCompressed,
+		EncodeRuntimeASCII85,
+	} {
+		config, err := encode(fullRuntime())
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(config)
+	}
+	for _, data := range jsonSeeds {
+		f.Add(base64.StdEncoding.EncodeToString([]byte(data)))
+	}
+	f.Add("")
+	f.Add("not base64!")
+	f.Add("<~~>")
+}
+
+func FuzzParseRuntime(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, config string) {
+		// Parsing must only ever fail with an error, never a panic.
+		for _, opts := range [][]ParseOption{
+			nil,
+			{WithStrict()},
+			{WithValidate(), WithDefaults()},
+		} {
+			_, _ = ParseRuntimeOptions(config, opts...)
+		}
+	})
+}
+
+func FuzzRuntimeRoundTrip(f *testing.F) {
+	// Fuzz the JSON directly rather than the encoded config,
+	// since mutating base64 rarely produces valid JSON.
+	full, err := json.Marshal(fullRuntime())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(full)
+	for _, data := range jsonSeeds {
+		f.Add([]byte(data))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cfg, err := ParseRuntimeErr(base64.StdEncoding.EncodeToString(data), "")
+		if err != nil {
+			return
+		}
+		encoded, err := EncodeRuntime(cfg)
+		if err != nil {
+			t.Fatalf("EncodeRuntime: %v", err)
+		}
+		got, err := ParseRuntimeErr(encoded, "")
+		if err != nil {
+			t.Fatalf("parsing the encoded config: %v", err)
+		}
+		// Empty slices and maps are omitted when encoding, so compare
+		// semantically: they're equivalent to nil ones.
+		if !got.Equal(cfg) {
+			t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, cfg)
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//go:generate go test -run TestRuntimeSchemaUpToDate -update
+
+//go:embed runtime.schema.json
+var runtimeSchema []byte
+
+// RuntimeSchema returns the JSON Schema describing the JSON form of [Runtime].
+//
+// The returned slice must not be modified.
+func RuntimeSchema() []byte {
+	return runtimeSchema
+}
+
+// ValidateRuntimeJSON validates the JSON-encoded runtime config in raw
+// against [RuntimeSchema], before it is unmarshaled.
+//
+// Each reported problem names the offending field using a JSON Pointer,
+// and all problems are joined together using [errors.Join].
+func ValidateRuntimeJSON(raw []byte) error {
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("invalid json: %w", err)
+	}
+	return errors.Join(parsedRuntimeSchema().validate("", doc)...)
+}
+
+// jsonSchema is the subset of JSON Schema used by the runtime config schema.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 schemaType             `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+}
+
+// schemaType is the set of JSON types a value may have.
+// It is encoded as a single string if there is only one type.
+type schemaType []string
+
+func (t schemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = schemaType{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+var parsedRuntimeSchema = sync.OnceValue(func() *jsonSchema {
+	var s jsonSchema
+	if err := json.Unmarshal(runtimeSchema, &s); err != nil {
+		panic("config: invalid embedded runtime schema: " + err.Error())
+	}
+	return &s
+})
+
+// validate reports the problems with the value v found at the given JSON Pointer path.
+func (s *jsonSchema) validate(path string, v any) []error {
+	if len(s.Type) > 0 && !slices.Contains(s.Type, jsonTypeOf(v)) {
+		// Integers are also numbers.
+		if !(jsonTypeOf(v) == "integer" && slices.Contains(s.Type, "number")) {
+			return []error{fmt.Errorf("%s: got %s, want %s", fieldPath(path), jsonTypeOf(v), joinTypes(s.Type))}
+		}
+	}
+
+	var errs []error
+	switch v := v.(type) {
+	case nil:
+		if err := s.checkEnum(v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fieldPath(path), err))
+		}
+
+	case string:
+		if err := s.checkEnum(v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fieldPath(path), err))
+		}
+		if err := checkFormat(s.Format, v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fieldPath(path), err))
+		}
+		if err := checkPattern(s.Pattern, v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fieldPath(path), err))
+		}
+
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s: missing required field", fieldPath(path+"/"+name)))
+			}
+		}
+		for name, val := range v {
+			if prop, ok := s.Properties[name]; ok {
+				errs = append(errs, prop.validate(path+"/"+name, val)...)
+			} else if s.AdditionalProperties != nil {
+				errs = append(errs, s.AdditionalProperties.validate(path+"/"+name, val)...)
+			}
+		}
+
+	case []any:
+		if s.Items != nil {
+			for i, val := range v {
+				errs = append(errs, s.Items.validate(path+"/"+strconv.Itoa(i), val)...)
+			}
+		}
+	}
+	return errs
+}
+
+// checkEnum reports whether the null or string value v is
+// one of the values allowed by s, if s restricts them.
+func (s *jsonSchema) checkEnum(v any) error {
+	if len(s.Enum) == 0 || slices.Contains(s.Enum, v) {
+		return nil
+	}
+	got, _ := json.Marshal(v)
+	want, _ := json.Marshal(s.Enum)
+	return fmt.Errorf("invalid value %s (must be one of %s)", got, want)
+}
+
+func checkPattern(pattern, v string) error {
+	if pattern == "" {
+		return nil
+	}
+	if ok, err := regexp.MatchString(pattern, v); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	} else if !ok {
+		return fmt.Errorf("%q does not match pattern %q", v, pattern)
+	}
+	return nil
+}
+
+func checkFormat(format, v string) error {
+	switch format {
+	case "uri":
+		if u, err := url.Parse(v); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid uri %q", v)
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+			return fmt.Errorf("invalid date-time %q", v)
+		}
+	}
+	return nil
+}
+
+// jsonTypeOf returns the JSON Schema type name of a value
+// unmarshaled by encoding/json.
+func jsonTypeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func joinTypes(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return fmt.Sprintf("one of %q", types)
+}
+
+func fieldPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update generated files")
+
+// generateRuntimeSchema generates the JSON Schema for Runtime
+// from the Go type definitions.
+func generateRuntimeSchema() *jsonSchema {
+	s := schemaFor(reflect.TypeOf(Runtime{}))
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	s.Title = "Encore runtime config"
+	// The parser requires an http or https API base URL, and
+	// unmarshaling null into it leaves it missing.
+	s.Required = []string{"api_base_url"}
+	apiBaseURL := s.Properties["api_base_url"]
+	apiBaseURL.Type = schemaType{"string"}
+	apiBaseURL.Format = "uri"
+	apiBaseURL.Pattern = "^[hH][tT][tT][pP][sS]?://"
+	// The parser accepts an empty env type and cloud, and the
+	// CLI omits the cloud for local development.
+	s.Properties["env_type"].Enum = enumOf(envTypes)
+	s.Properties["env_cloud"].Enum = enumOf(envClouds)
+	return s
+}
+
+// enumOf returns the enum of a nullable string field that may
+// be empty or hold one of the given values.
+func enumOf(values []string) []any {
+	enum := []any{nil, ""}
+	for _, v := range values {
+		enum = append(enum, v)
+	}
+	return enum
+}
+
+// schemaFor returns the schema of values of type t. Unmarshaling null
+// into any Go value is a no-op, so every nested field also accepts null.
+func schemaFor(t reflect.Type) *jsonSchema {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return &jsonSchema{Type: schemaType{"string"}, Format: "date-time"}
+	case reflect.TypeOf(Duration(0)):
+		return &jsonSchema{Type: schemaType{"integer", "string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.Struct:
+		s := &jsonSchema{Type: schemaType{"object"}, Properties: make(map[string]*jsonSchema)}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			} else if name == "" {
+				name = f.Name
+			}
+			s.Properties[name] = nullable(schemaFor(f.Type))
+		}
+		return s
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &jsonSchema{Type: schemaType{"string"}}
+		}
+		return &jsonSchema{Type: schemaType{"array"}, Items: nullable(schemaFor(t.Elem()))}
+	case reflect.Map:
+		return &jsonSchema{Type: schemaType{"object"}, AdditionalProperties: nullable(schemaFor(t.Elem()))}
+	case reflect.String:
+		return &jsonSchema{Type: schemaType{"string"}}
+	case reflect.Bool:
+		return &jsonSchema{Type: schemaType{"boolean"}}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: schemaType{"number"}}
+	default:
+		return &jsonSchema{Type: schemaType{"integer"}}
+	}
+}
+
+func nullable(s *jsonSchema) *jsonSchema {
+	s.Type = append(s.Type, "null")
+	return s
+}
+
+func TestRuntimeSchemaUpToDate(t *testing.T) {
+	want, err := json.MarshalIndent(generateRuntimeSchema(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, '\n')
+
+	if *update {
+		if err := os.WriteFile("runtime.schema.json", want, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if !bytes.Equal(RuntimeSchema(), want) {
+		t.Error("runtime.schema.json is out of date; run go generate to update it")
+	}
+
+}
+
+// TestRuntimeSchemaMatchesParser checks that the schema accepts
+// exactly the configs that ParseRuntimeValidated accepts.
+func TestRuntimeSchemaMatchesParser(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		valid  bool
+	}{
+		{
+			name:   "minimal_local",
+			config: `{"app_id": "app", "api_base_url": "http://localhost:4000", "env_name": "local"}`,
+			valid:  true,
+		},
+		{
+			name:   "empty_env",
+			config: `{"app_id": "app", "api_base_url": "http://localhost:4000", "env_type": "", "env_cloud": ""}`,
+			valid:  true,
+		},
+		{
+			name:   "no_app_id",
+			config: `{"api_base_url": "http://localhost:4000"}`,
+			valid:  true,
+		},
+		{
+			name:   "null_fields",
+			config: `{"app_id": null, "api_base_url": "http://localhost:4000", "env_type": null, "graceful_shutdown": {"total": null}}`,
+			valid:  true,
+		},
+		{
+			name:   "upper_case_scheme",
+			config: `{"api_base_url": "HTTPS://example.com"}`,
+			valid:  true,
+		},
+		{
+			name:   "ftp_api_base_url",
+			config: `{"api_base_url": "ftp://example.com"}`,
+		},
+		{
+			name:   "null_api_base_url",
+			config: `{"api_base_url": null}`,
+		},
+		{
+			name:   "no_api_base_url",
+			config: `{"app_id": "app"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, parseErr := ParseRuntimeValidated(base64.StdEncoding.EncodeToString([]byte(tt.config)), "")
+			schemaErr := ValidateRuntimeJSON([]byte(tt.config))
+			if got := parseErr == nil; got != tt.valid {
+				t.Errorf("ParseRuntimeValidated: got valid=%v, want %v (err: %v)", got, tt.valid, parseErr)
+			}
+			if got := schemaErr == nil; got != tt.valid {
+				t.Errorf("ValidateRuntimeJSON: got valid=%v, want %v (err: %v)", got, tt.valid, schemaErr)
+			}
+		})
+	}
+}
+
+func TestValidateRuntimeJSON(t *testing.T) {
+	valid, err := json.Marshal(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateRuntimeJSON(valid); err != nil {
+		t.Errorf("valid config: unexpected error: %v", err)
+	}
+
+	malformed := []byte(`{
+		"app_id": 5,
+		"api_base_url": "not-a-url",
+		"env_type": "prod",
+		"sql_databases": [{"server_id": "zero"}],
+		"graceful_shutdown": {"total": "10s"}
+	}`)
+	err = ValidateRuntimeJSON(malformed)
+	if err == nil {
+		t.Fatal("malformed config: expected an error, got nil")
+	}
+	for _, want := range []string{
+		"/app_id: got integer, want one of",
+		`/api_base_url: invalid uri "not-a-url"`,
+		`/env_type: invalid value "prod"`,
+		"/sql_databases/0/server_id: got string, want one of",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "graceful_shutdown") {
+		t.Errorf("duration strings should be accepted, got %v", err)
+	}
+
+	if err := ValidateRuntimeJSON([]byte("{")); err == nil {
+		t.Error("invalid json: expected an error, got nil")
+	}
+}
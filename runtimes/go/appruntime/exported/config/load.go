@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"encore.dev/appruntime/shared/encoreenv"
+)
+
+var (
+	loadOnce    sync.Once
+	loadRuntime *Runtime
+)
+
+// Load parses the runtime config from the ENCORE_RUNTIME_CONFIG
+// environment variable, using ENCORE_DEPLOY_ID to override the deploy id
+// if set. It terminates the process if the config cannot be parsed.
+//
+// If ENCORE_RUNTIME_CONFIG is not set, the config is read from chunks in
+// ENCORE_RUNTIME_CONFIG_0, ENCORE_RUNTIME_CONFIG_1, etc, as with
+// [ParseRuntimeChunks]. A missing index is a fatal error.
+//
+// The config is only decoded and parsed on the first call;
+// subsequent calls return the same cached *Runtime.
+func Load() *Runtime {
+	loadOnce.Do(func() {
+		config := encoreenv.Get("ENCORE_RUNTIME_CONFIG")
+		deployID := encoreenv.Get("ENCORE_DEPLOY_ID")
+		if cleanConfig(config) == "" {
+			if chunks := encoreenvChunks("ENCORE_RUNTIME_CONFIG"); len(chunks) > 0 {
+				cfg, err := ParseRuntimeChunks(chunks, deployID)
+				if err != nil {
+					log.Fatalln("encore runtime: fatal error:", err)
+				}
+				loadRuntime = cfg
+				return
+			}
+		}
+		loadRuntime = ParseRuntime(config, deployID)
+	})
+	return loadRuntime
+}
+
+// encoreenvChunks returns the values of <prefix>_0, <prefix>_1, etc,
+// as with [RuntimeChunksFromEnv]. Variables set to the empty string
+// are treated as unset, as with [encoreenv.Get].
+//
+// Encore's own variables are removed from the environment at startup,
+// so they're read from encoreenv instead.
+func encoreenvChunks(prefix string) []string {
+	var environ []string
+	for _, kv := range encoreenv.Environ() {
+		if !strings.HasSuffix(kv, "=") {
+			environ = append(environ, kv)
+		}
+	}
+	return RuntimeChunksFromEnv(environ, prefix)
+}
+
+// ParseRuntimeFromEnv is like [ParseRuntimeErr] but reads the config
+// from the environment variable named varName, and the deploy ID
+// override from the one named deployIDVar.
+//
+// As with [Load], a non-empty deploy ID from the environment takes
+// precedence over the one embedded in the config. If deployIDVar is
+// empty the embedded deploy ID is always used.
+func ParseRuntimeFromEnv(varName, deployIDVar string) (*Runtime, error) {
+	config := getenv(varName)
+	if cleanConfig(config) == "" {
+		return nil, &ParseError{Stage: StageDecode, Err: fmt.Errorf("%w: environment variable %s is not set", errNoRuntimeConfig, varName)}
+	}
+	var deployID string
+	if deployIDVar != "" {
+		deployID = getenv(deployIDVar)
+	}
+	return ParseRuntimeErr(config, deployID)
+}
+
+// getenv returns the value of the environment variable name.
+//
+// Encore's own variables are captured and removed from the environment
+// at startup, so those are read from encoreenv first.
+func getenv(name string) string {
+	if v := encoreenv.Get(name); v != "" {
+		return v
+	}
+	return os.Getenv(name)
+}
+
+// resetForTest clears the config cached by Load.
+func resetForTest() {
+	loadOnce = sync.Once{}
+	loadRuntime = nil
+}
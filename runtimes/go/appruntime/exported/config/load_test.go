@@ -0,0 +1,218 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"encore.dev/appruntime/shared/encoreenv"
+)
+
+func setLoadEnv(t testing.TB, config, deployID string) {
+	t.Helper()
+	encoreenv.Set("ENCORE_RUNTIME_CONFIG", config)
+	encoreenv.Set("ENCORE_DEPLOY_ID", deployID)
+	resetForTest()
+	t.Cleanup(func() {
+		encoreenv.Set("ENCORE_RUNTIME_CONFIG", "")
+		encoreenv.Set("ENCORE_DEPLOY_ID", "")
+		resetForTest()
+	})
+}
+
+func TestLoad(t *testing.T) {
+	config, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setLoadEnv(t, config, "override")
+
+	cfg := Load()
+	if cfg.AppID != "app-id" || cfg.DeployID != "override" {
+		t.Errorf("got app id %q, deploy id %q", cfg.AppID, cfg.DeployID)
+	}
+
+	// Changing the environment has no effect once loaded,
+	// since the config isn't decoded again.
+	encoreenv.Set("ENCORE_RUNTIME_CONFIG", "not base64!")
+	if again := Load(); again != cfg {
+		t.Error("Load returned a different config on the second call")
+	}
+}
+
+func TestLoadChunks(t *testing.T) {
+	config, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setLoadEnv(t, "", "override")
+
+	// The chunks are read from encoreenv, since Encore's own variables
+	// are removed from the environment when running as an app.
+	half := len(config) / 2
+	for i, chunk := range []string{config[:half], config[half:]} {
+		name := "ENCORE_RUNTIME_CONFIG_" + strconv.Itoa(i)
+		encoreenv.Set(name, chunk)
+		t.Cleanup(func() { encoreenv.Set(name, "") })
+	}
+
+	cfg := Load()
+	if cfg.AppID != "app-id" || cfg.DeployID != "override" {
+		t.Errorf("got app id %q, deploy id %q", cfg.AppID, cfg.DeployID)
+	}
+}
+
+func TestEncoreenvChunksGap(t *testing.T) {
+	for _, name := range []string{"ENCORE_TEST_CHUNKS_0", "ENCORE_TEST_CHUNKS_2"} {
+		encoreenv.Set(name, "chunk")
+		t.Cleanup(func() { encoreenv.Set(name, "") })
+	}
+	// Variables cleared by setting them to the empty string are unset.
+	encoreenv.Set("ENCORE_TEST_CHUNKS_3", "")
+
+	chunks := encoreenvChunks("ENCORE_TEST_CHUNKS")
+	if want := []string{"chunk", "", "chunk"}; !slices.Equal(chunks, want) {
+		t.Fatalf("got chunks %q, want %q", chunks, want)
+	}
+	_, err := ParseRuntimeChunks(chunks, "")
+	if err == nil || !strings.Contains(err.Error(), "chunk 1 is missing") {
+		t.Errorf("ParseRuntimeChunks: got error %v, want chunk 1 to be missing", err)
+	}
+}
+
+// BenchmarkLoad demonstrates that repeated calls to Load
+// don't decode the config again.
+func BenchmarkLoad(b *testing.B) {
+	config, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Load", func(b *testing.B) {
+		setLoadEnv(b, config, "")
+		for i := 0; i < b.N; i++ {
+			Load()
+		}
+	})
+	b.Run("ParseRuntime", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ParseRuntime(config, "")
+		}
+	})
+}
+
+func TestParseRuntimeFromEnv(t *testing.T) {
+	config, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("present", func(t *testing.T) {
+		t.Setenv("PLATFORM_RUNTIME_CONFIG", config)
+		cfg, err := ParseRuntimeFromEnv("PLATFORM_RUNTIME_CONFIG", "PLATFORM_DEPLOY_ID")
+		if err != nil {
+			t.Fatalf("ParseRuntimeFromEnv: %v", err)
+		}
+		if cfg.AppID != "app-id" || cfg.DeployID != fullRuntime().DeployID {
+			t.Errorf("got app id %q, deploy id %q", cfg.AppID, cfg.DeployID)
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		t.Setenv("PLATFORM_RUNTIME_CONFIG", config)
+		t.Setenv("PLATFORM_DEPLOY_ID", "override")
+		cfg, err := ParseRuntimeFromEnv("PLATFORM_RUNTIME_CONFIG", "PLATFORM_DEPLOY_ID")
+		if err != nil {
+			t.Fatalf("ParseRuntimeFromEnv: %v", err)
+		}
+		if cfg.DeployID != "override" {
+			t.Errorf("got deploy id %q, want %q", cfg.DeployID, "override")
+		}
+
+		// Without a deploy ID variable the embedded one is used.
+		cfg, err = ParseRuntimeFromEnv("PLATFORM_RUNTIME_CONFIG", "")
+		if err != nil {
+			t.Fatalf("ParseRuntimeFromEnv: %v", err)
+		}
+		if cfg.DeployID != fullRuntime().DeployID {
+			t.Errorf("no deploy id var: got deploy id %q", cfg.DeployID)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		_, err := ParseRuntimeFromEnv("PLATFORM_MISSING_CONFIG", "PLATFORM_DEPLOY_ID")
+		if !errors.Is(err, errNoRuntimeConfig) {
+			t.Errorf("got %v, want %v", err, errNoRuntimeConfig)
+		}
+		if err == nil || !strings.Contains(err.Error(), "PLATFORM_MISSING_CONFIG") {
+			t.Errorf("error %v does not name the variable", err)
+		}
+	})
+
+	t.Run("encoreenv", func(t *testing.T) {
+		setLoadEnv(t, config, "from-encoreenv")
+		cfg, err := ParseRuntimeFromEnv("ENCORE_RUNTIME_CONFIG", "ENCORE_DEPLOY_ID")
+		if err != nil {
+			t.Fatalf("ParseRuntimeFromEnv: %v", err)
+		}
+		if cfg.DeployID != "from-encoreenv" {
+			t.Errorf("got deploy id %q, want %q", cfg.DeployID, "from-encoreenv")
+		}
+	})
+}
+
+func TestWithDeployIDSources(t *testing.T) {
+	config, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedded := fullRuntime().DeployID
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "first_source_wins",
+			env:  map[string]string{"TEST_DEPLOY_ID_A": "from-a", "TEST_DEPLOY_ID_B": "from-b"},
+			want: "from-a",
+		},
+		{
+			name: "later_source_fallback",
+			env:  map[string]string{"TEST_DEPLOY_ID_A": "", "TEST_DEPLOY_ID_B": "from-b"},
+			want: "from-b",
+		},
+		{
+			name: "all_empty",
+			env:  map[string]string{"TEST_DEPLOY_ID_A": "", "TEST_DEPLOY_ID_B": ""},
+			want: embedded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			cfg, err := ParseRuntimeOptions(config, WithDeployIDSources("TEST_DEPLOY_ID_A", "TEST_DEPLOY_ID_B"))
+			if err != nil {
+				t.Fatalf("ParseRuntimeOptions: %v", err)
+			}
+			if cfg.DeployID != tt.want {
+				t.Errorf("got deploy id %q, want %q", cfg.DeployID, tt.want)
+			}
+		})
+	}
+
+	// An explicit deploy ID takes precedence.
+	t.Setenv("TEST_DEPLOY_ID_A", "from-a")
+	cfg, err := ParseRuntimeOptions(config, WithDeployID("explicit"), WithDeployIDSources("TEST_DEPLOY_ID_A"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DeployID != "explicit" {
+		t.Errorf("got deploy id %q, want %q", cfg.DeployID, "explicit")
+	}
+}
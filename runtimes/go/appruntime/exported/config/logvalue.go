@@ -0,0 +1,37 @@
+package config
+
+import "log/slog"
+
+// LogValue implements [slog.LogValuer], summarizing the runtime config
+// for structured logs. It includes the app and environment metadata
+// and the number of each kind of resource, but no secrets and none
+// of the nested configuration.
+func (r *Runtime) LogValue() slog.Value {
+	if r == nil {
+		return slog.StringValue("<nil>")
+	}
+	attrs := []slog.Attr{
+		slog.String("app_id", r.AppID),
+		slog.String("app_slug", r.AppSlug),
+		slog.String("env_name", r.EnvName),
+		slog.String("env_type", r.EnvType),
+		slog.String("env_cloud", r.EnvCloud),
+		slog.String("deploy_id", r.DeployID),
+	}
+	if !r.DeployedAt.IsZero() {
+		attrs = append(attrs, slog.Time("deploy_time", r.DeployedAt))
+	}
+	attrs = append(attrs,
+		slog.Int("schema_version", r.SchemaVersion),
+		slog.Int("auth_keys", len(r.AuthKeys)),
+		slog.Int("sql_servers", len(r.SQLServers)),
+		slog.Int("sql_databases", len(r.SQLDatabases)),
+		slog.Int("redis_servers", len(r.RedisServers)),
+		slog.Int("redis_databases", len(r.RedisDatabases)),
+		slog.Int("pubsub_providers", len(r.PubsubProviders)),
+		slog.Int("pubsub_topics", len(r.PubsubTopics)),
+		slog.Int("gateways", len(r.Gateways)),
+		slog.Int("hosted_services", len(r.HostedServices)),
+	)
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRuntimeLogValue(t *testing.T) {
+	cfg := fullRuntime()
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("starting", "config", cfg)
+
+	var entry struct {
+		Config map[string]any `json:"config"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal log entry %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"app_id":        cfg.AppID,
+		"env_name":      cfg.EnvName,
+		"env_cloud":     cfg.EnvCloud,
+		"deploy_id":     cfg.DeployID,
+		"sql_databases": float64(len(cfg.SQLDatabases)),
+		"pubsub_topics": float64(len(cfg.PubsubTopics)),
+	}
+	for k, v := range want {
+		if got := entry.Config[k]; got != v {
+			t.Errorf("%s: got %v, want %v", k, got, v)
+		}
+	}
+
+	for _, secret := range []string{"secret", "PRIVATE KEY"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("log entry %q contains secret %q", buf.String(), secret)
+		}
+	}
+}
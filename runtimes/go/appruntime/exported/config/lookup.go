@@ -0,0 +1,65 @@
+package config
+
+import "slices"
+
+// SQLDatabase returns the SQL database with the given Encore name.
+func (r *Runtime) SQLDatabase(encoreName string) (*SQLDatabase, bool) {
+	for _, db := range r.SQLDatabases {
+		if db.EncoreName == encoreName {
+			return db, true
+		}
+	}
+	return nil, false
+}
+
+// SQLServer returns the SQL server with the given id,
+// which is its index into r.SQLServers.
+func (r *Runtime) SQLServer(id int) (*SQLServer, bool) {
+	if id < 0 || id >= len(r.SQLServers) {
+		return nil, false
+	}
+	return r.SQLServers[id], true
+}
+
+// RedisDatabase returns the Redis database with the given Encore name.
+func (r *Runtime) RedisDatabase(encoreName string) (*RedisDatabase, bool) {
+	for _, db := range r.RedisDatabases {
+		if db.EncoreName == encoreName {
+			return db, true
+		}
+	}
+	return nil, false
+}
+
+// RedisServer returns the Redis server with the given id,
+// which is its index into r.RedisServers.
+func (r *Runtime) RedisServer(id int) (*RedisServer, bool) {
+	if id < 0 || id >= len(r.RedisServers) {
+		return nil, false
+	}
+	return r.RedisServers[id], true
+}
+
+// Gateway returns the gateway with the given name.
+func (r *Runtime) Gateway(name string) (*Gateway, bool) {
+	for i := range r.Gateways {
+		if r.Gateways[i].Name == name {
+			return &r.Gateways[i], true
+		}
+	}
+	return nil, false
+}
+
+// HostedServiceNames returns the sorted, deduplicated names of
+// the services hosted in this container.
+//
+// It returns nil if the config doesn't list any hosted services,
+// in which case all services are hosted unless a gateway is running.
+func (r *Runtime) HostedServiceNames() []string {
+	if len(r.HostedServices) == 0 {
+		return nil
+	}
+	names := slices.Clone(r.HostedServices)
+	slices.Sort(names)
+	return slices.Compact(names)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSQLDatabase(t *testing.T) {
+	cfg := fullRuntime()
+	if db, ok := cfg.SQLDatabase("users"); !ok || db != cfg.SQLDatabases[0] {
+		t.Errorf("found: got %v, %v", db, ok)
+	}
+	if db, ok := cfg.SQLDatabase("missing"); ok || db != nil {
+		t.Errorf("not found: got %v, %v", db, ok)
+	}
+	if db, ok := (&Runtime{}).SQLDatabase("users"); ok || db != nil {
+		t.Errorf("empty: got %v, %v", db, ok)
+	}
+}
+
+func TestSQLServer(t *testing.T) {
+	cfg := fullRuntime()
+	if srv, ok := cfg.SQLServer(0); !ok || srv != cfg.SQLServers[0] {
+		t.Errorf("found: got %v, %v", srv, ok)
+	}
+	for _, id := range []int{-1, 1} {
+		if srv, ok := cfg.SQLServer(id); ok || srv != nil {
+			t.Errorf("not found (id %d): got %v, %v", id, srv, ok)
+		}
+	}
+	if srv, ok := (&Runtime{}).SQLServer(0); ok || srv != nil {
+		t.Errorf("empty: got %v, %v", srv, ok)
+	}
+}
+
+func TestGateway(t *testing.T) {
+	cfg := fullRuntime()
+	gw, ok := cfg.Gateway("api-gateway")
+	if !ok || gw.Host != "api.example.com" {
+		t.Fatalf("Gateway(%q) = %+v, %v", "api-gateway", gw, ok)
+	}
+
+	// The returned gateway refers to the one in the config.
+	gw.Host = "changed.example.com"
+	if cfg.Gateways[0].Host != "changed.example.com" {
+		t.Error("Gateway returned a copy")
+	}
+
+	if _, ok := cfg.Gateway("missing"); ok {
+		t.Error("Gateway(missing): got ok")
+	}
+}
+
+func TestHostedServiceNames(t *testing.T) {
+	cfg := &Runtime{HostedServices: []string{"users", "billing", "users"}}
+	if got, want := cfg.HostedServiceNames(), []string{"billing", "users"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if cfg.HostedServices[0] != "users" {
+		t.Errorf("HostedServices was modified: %v", cfg.HostedServices)
+	}
+	if got := (&Runtime{}).HostedServiceNames(); got != nil {
+		t.Errorf("no hosted services: got %v, want nil", got)
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"reflect"
+	"time"
+)
+
+// Merge applies the non-zero fields of overlay onto r.
+//
+// The rule is "non-zero wins": every field that is set in overlay replaces
+// the corresponding field in r, and fields left at their zero value in
+// overlay are kept as-is in r. As a consequence Merge cannot be used to
+// reset a field to its zero value.
+//
+// Nested structs are merged field by field. Slices whose elements have an
+// identity are merged element by element rather than appended to:
+// SQL and Redis servers are matched by their index (their server id),
+// SQL and Redis databases by their Encore name, and gateways by their name.
+// Overlay elements without a match in r are appended. Maps are merged key
+// by key. All other slices are replaced wholesale.
+//
+// The overlay is not modified, and r does not share any memory with it afterwards.
+//
+// It returns [ErrFrozen] without modifying r if r is frozen.
+func (r *Runtime) Merge(overlay *Runtime) error {
+	if r.frozen {
+		return ErrFrozen
+	}
+	if overlay == nil {
+		return nil
+	}
+	overlay = overlay.Clone()
+	mergeValue(reflect.ValueOf(r).Elem(), reflect.ValueOf(overlay).Elem())
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// mergeValue merges src into dst according to the rules documented on [Runtime.Merge].
+func mergeValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		if src.Type() == timeType {
+			if !src.IsZero() {
+				dst.Set(src)
+			}
+			return
+		}
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).IsExported() {
+				mergeValue(dst.Field(i), src.Field(i))
+			}
+		}
+
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		} else if dst.IsNil() || src.Elem().Kind() != reflect.Struct {
+			dst.Set(src)
+			return
+		}
+		mergeValue(dst.Elem(), src.Elem())
+
+	case reflect.Slice:
+		if src.Len() == 0 {
+			return
+		}
+		switch src.Interface().(type) {
+		case []*SQLServer, []*RedisServer, []*SQLDatabase, []*RedisDatabase, []Gateway:
+			mergeSlice(dst, src)
+		default:
+			dst.Set(src)
+		}
+
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		} else if dst.IsNil() {
+			dst.Set(src)
+			return
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			k, v := iter.Key(), iter.Value()
+			existing := dst.MapIndex(k)
+			if !existing.IsValid() || v.Kind() != reflect.Pointer || existing.IsNil() {
+				dst.SetMapIndex(k, v)
+				continue
+			}
+			mergeValue(existing, v)
+		}
+
+	default:
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+// mergeSlice merges the elements of src into the elements of dst
+// that have the same identity, and appends the rest.
+func mergeSlice(dst, src reflect.Value) {
+	index := make(map[any]int, dst.Len())
+	for i := 0; i < dst.Len(); i++ {
+		index[identity(dst.Index(i), i)] = i
+	}
+	for i := 0; i < src.Len(); i++ {
+		elem := src.Index(i)
+		if j, ok := index[identity(elem, i)]; ok && !dst.Index(j).IsZero() {
+			mergeValue(dst.Index(j), elem)
+		} else {
+			dst.Set(reflect.Append(dst, elem))
+		}
+	}
+}
+
+// identity returns the key identifying the slice element v at index idx.
+func identity(v reflect.Value, idx int) any {
+	switch v := v.Interface().(type) {
+	case *SQLDatabase:
+		if v != nil {
+			return v.EncoreName
+		}
+	case *RedisDatabase:
+		if v != nil {
+			return v.EncoreName
+		}
+	case Gateway:
+		return v.Name
+	}
+	return idx
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	cfg := fullRuntime()
+	overlay := &Runtime{
+		APIBaseURL: "http://localhost:4000",
+		SQLServers: []*SQLServer{{Host: "localhost:5432"}},
+		SQLDatabases: []*SQLDatabase{
+			{EncoreName: "users", Password: "local-password"},
+			{EncoreName: "orders", DatabaseName: "orders-db"},
+		},
+		Gateways: []Gateway{{Name: "api-gateway", Host: "localhost"}},
+	}
+	cfg.Merge(overlay)
+
+	want := fullRuntime()
+	want.APIBaseURL = "http://localhost:4000"
+	want.SQLServers[0].Host = "localhost:5432"
+	want.SQLDatabases[0].Password = "local-password"
+	want.SQLDatabases = append(want.SQLDatabases, &SQLDatabase{EncoreName: "orders", DatabaseName: "orders-db"})
+	want.Gateways[0].Host = "localhost"
+
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("merge mismatch:\ngot  %+v\nwant %+v", cfg, want)
+	}
+
+	// The merged config must not alias the overlay.
+	overlay.SQLDatabases[1].DatabaseName = "changed"
+	if got := cfg.SQLDatabases[1].DatabaseName; got != "orders-db" {
+		t.Errorf("merged config aliases overlay: got database name %q", got)
+	}
+}
+
+func TestMergeZeroOverlay(t *testing.T) {
+	cfg := fullRuntime()
+	cfg.Merge(&Runtime{})
+	cfg.Merge(nil)
+	if !reflect.DeepEqual(cfg, fullRuntime()) {
+		t.Errorf("merging an empty overlay modified the config:\n%+v", cfg)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// MetricsEnabled reports whether a metrics provider is configured.
+func (r *Runtime) MetricsEnabled() bool {
+	m := r.Metrics
+	return m != nil && (m.EncoreCloud != nil ||
+		m.CloudMonitoring != nil ||
+		m.CloudWatch != nil ||
+		m.LogsBased != nil ||
+		m.Prometheus != nil ||
+		m.Datadog != nil)
+}
+
+// validateMetrics checks that each configured metrics provider
+// has the fields it needs to export metrics.
+func (r *Runtime) validateMetrics() []error {
+	m := r.Metrics
+	if m == nil {
+		return nil
+	}
+
+	var errs []error
+	missing := func(provider, field string) {
+		errs = append(errs, fmt.Errorf("metrics provider %s: missing %s", provider, field))
+	}
+
+	for _, p := range []struct {
+		name string
+		cfg  *GCPCloudMonitoringProvider
+	}{
+		{"encore_cloud", m.EncoreCloud},
+		{"gcp_cloud_monitoring", m.CloudMonitoring},
+	} {
+		if p.cfg == nil {
+			continue
+		}
+		if p.cfg.ProjectID == "" {
+			missing(p.name, "project id")
+		}
+		if p.cfg.MonitoredResourceType == "" {
+			missing(p.name, "monitored resource type")
+		}
+	}
+	if m.CloudWatch != nil && m.CloudWatch.Namespace == "" {
+		missing("aws_cloud_watch", "namespace")
+	}
+	if m.Prometheus != nil {
+		if m.Prometheus.RemoteWriteURL == "" {
+			missing("prometheus", "remote write url")
+		} else if u, err := url.Parse(m.Prometheus.RemoteWriteURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("metrics provider prometheus: invalid remote write url %q", m.Prometheus.RemoteWriteURL))
+		}
+	}
+	if m.Datadog != nil {
+		if m.Datadog.Site == "" {
+			missing("datadog", "site")
+		}
+		if m.Datadog.APIKey == "" {
+			missing("datadog", "api key")
+		}
+	}
+	return errs
+}
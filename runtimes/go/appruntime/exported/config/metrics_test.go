@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateMetrics(t *testing.T) {
+	tests := []struct {
+		name        string
+		metrics     *Metrics
+		wantEnabled bool
+		wantErrs    []string
+	}{
+		{
+			name:        "disabled",
+			metrics:     nil,
+			wantEnabled: false,
+		},
+		{
+			name:        "no_provider",
+			metrics:     &Metrics{},
+			wantEnabled: false,
+		},
+		{
+			name:        "logs_based",
+			metrics:     &Metrics{LogsBased: &LogsBasedMetricsProvider{}},
+			wantEnabled: true,
+		},
+		{
+			name:        "valid_prometheus",
+			metrics:     &Metrics{Prometheus: &PrometheusRemoteWriteProvider{RemoteWriteURL: "https://prom.example.com/api/v1/write"}},
+			wantEnabled: true,
+		},
+		{
+			name:        "prometheus_missing_endpoint",
+			metrics:     &Metrics{Prometheus: &PrometheusRemoteWriteProvider{}},
+			wantEnabled: true,
+			wantErrs:    []string{"metrics provider prometheus: missing remote write url"},
+		},
+		{
+			name:        "prometheus_invalid_endpoint",
+			metrics:     &Metrics{Prometheus: &PrometheusRemoteWriteProvider{RemoteWriteURL: "prom.example.com"}},
+			wantEnabled: true,
+			wantErrs:    []string{`metrics provider prometheus: invalid remote write url "prom.example.com"`},
+		},
+		{
+			name:        "datadog_missing_auth",
+			metrics:     &Metrics{Datadog: &DatadogProvider{Site: "datadoghq.com"}},
+			wantEnabled: true,
+			wantErrs:    []string{"metrics provider datadog: missing api key"},
+		},
+		{
+			name:        "gcp_missing_fields",
+			metrics:     &Metrics{CloudMonitoring: &GCPCloudMonitoringProvider{}},
+			wantEnabled: true,
+			wantErrs: []string{
+				"metrics provider gcp_cloud_monitoring: missing project id",
+				"metrics provider gcp_cloud_monitoring: missing monitored resource type",
+			},
+		},
+		{
+			name:        "cloud_watch_missing_namespace",
+			metrics:     &Metrics{CloudWatch: &AWSCloudWatchMetricsProvider{}},
+			wantEnabled: true,
+			wantErrs:    []string{"metrics provider aws_cloud_watch: missing namespace"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Runtime{Metrics: tt.metrics}
+			if got := cfg.MetricsEnabled(); got != tt.wantEnabled {
+				t.Errorf("MetricsEnabled() = %v, want %v", got, tt.wantEnabled)
+			}
+			err := errors.Join(cfg.validateMetrics()...)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if got, want := err.Error(), strings.Join(tt.wantErrs, "\n"); got != want {
+				t.Errorf("got error %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"log/slog"
+	"maps"
+)
+
+// ParseOption configures how [ParseRuntimeOptions] parses the runtime config.
+type ParseOption func(*parseOptions)
+
+// parseOptions configures how the runtime config is parsed.
+type parseOptions struct {
+	deployID string // overrides the deploy ID in the config, if non-empty
+	// deployIDSources are environment variables to read the
+	// deploy ID override from, if deployID is empty.
+	deployIDSources []string
+	apiBaseURL      string // overrides the API base URL in the config, if non-empty
+
+	// skipAPIBaseURLCheck is set by the parse functions that terminate
+	// the process, which accept any API base URL unless validating.
+	skipAPIBaseURLCheck bool
+	// skipParseChecks skips the checks made on every parse,
+	// for callers that report them along with the rest of Validate.
+	skipParseChecks bool
+
+	// cloudFallback, if non-empty, replaces an unknown EnvCloud.
+	cloudFallback string
+	strict        bool // reject unknown fields
+	relaxed       bool // allow comments and trailing commas
+	extraFields   bool // keep unknown top-level fields in Runtime.Extra
+	cloudURLCheck bool // check the api base url suits the cloud
+
+	// expectedDatabases, if non-nil, are the Encore names of
+	// the only SQL databases the config may contain.
+	expectedDatabases []string
+	validate          bool // run (*Runtime).Validate after parsing
+	defaults          bool // run (*Runtime).ApplyDefaults after parsing
+
+	// lookup, if non-nil, is used to expand environment variable
+	// references in the config.
+	lookup func(string) (string, bool)
+
+	// sqlHosts, if non-nil, maps SQL server IDs or hosts to
+	// the hosts to connect to instead.
+	sqlHosts map[string]string
+
+	// resolveSecret, if non-nil, is used to resolve
+	// secret fields that reference a secret.
+	resolveSecret func(ref string) (string, error)
+
+	// decrypt, if non-nil, is used to decrypt the decoded config
+	// before it is decompressed and unmarshaled.
+	decrypt func([]byte) ([]byte, error)
+
+	// deprecated, if non-nil, is called for each populated
+	// deprecated field in the config.
+	deprecated func(field string)
+
+	// logger, if non-nil, is used to log parse failures.
+	logger *slog.Logger
+
+	// info, if non-nil, is filled in with how the config was encoded.
+	info *ParseInfo
+
+	// maxConfigBytes bounds the size of the decoded
+	// and decompressed config. It is always positive.
+	maxConfigBytes int
+
+	// validation configures the checks made when validate is set.
+	validation validateOptions
+}
+
+// WithDeployID overrides the deploy ID embedded in the config,
+// such as with one provided by the environment.
+// An empty deployID keeps the embedded one.
+func WithDeployID(deployID string) ParseOption {
+	return func(o *parseOptions) {
+		o.deployID = deployID
+	}
+}
+
+// WithDeployIDSources overrides the deploy ID embedded in the config
+// with the first non-empty value of the named environment variables,
+// for platforms that expose the deploy ID under different names.
+// If they're all empty the embedded deploy ID is kept.
+//
+// A deploy ID set using [WithDeployID] takes precedence.
+func WithDeployIDSources(names ...string) ParseOption {
+	return func(o *parseOptions) {
+		o.deployIDSources = names
+	}
+}
+
+// WithAPIBaseURL overrides the API base URL embedded in the config,
+// such as during a blue/green cutover. The override is validated like
+// the embedded value would be. An empty url keeps the embedded one.
+func WithAPIBaseURL(url string) ParseOption {
+	return func(o *parseOptions) {
+		o.apiBaseURL = url
+	}
+}
+
+// WithCloudFallback replaces the cloud in the config with fallback, such as
+// [CloudLocal], if it isn't one this version of the runtime knows about.
+// When that happens the handler set with [WithDeprecationHandler], if any,
+// is called with the field name "env_cloud".
+//
+// Without a fallback an unknown cloud is kept as is,
+// unless [WithStrict] is used in which case it's an error.
+func WithCloudFallback(fallback string) ParseOption {
+	return func(o *parseOptions) {
+		o.cloudFallback = fallback
+	}
+}
+
+// WithStrict rejects configs containing fields this version
+// of the runtime doesn't know about, or an unknown cloud.
+func WithStrict() ParseOption {
+	return func(o *parseOptions) {
+		o.strict = true
+	}
+}
+
+// WithRelaxedJSON accepts hand-written configs containing "//" line
+// comments and trailing commas, by stripping them from the decoded
+// config before it is unmarshaled. It is meant for local development;
+// without it, such configs are rejected.
+func WithRelaxedJSON() ParseOption {
+	return func(o *parseOptions) {
+		o.relaxed = true
+	}
+}
+
+// WithExtraFields keeps the top-level fields of the config that this
+// version of the runtime doesn't know about in [Runtime.Extra], so that
+// tooling can modify a newer config and re-encode it without losing them.
+// It costs another pass over the config, so apps don't use it.
+// With [WithStrict], unknown fields are rejected instead.
+func WithExtraFields() ParseOption {
+	return func(o *parseOptions) {
+		o.extraFields = true
+	}
+}
+
+// WithValidate normalizes the CORS config using [CORS.Normalize]
+// and checks the parsed config using [Runtime.Validate].
+func WithValidate() ParseOption {
+	return func(o *parseOptions) {
+		o.validate = true
+	}
+}
+
+// WithCloudURLCheck checks that the API base URL is one that can serve
+// the app in the cloud given by EnvCloud: apps in Encore Cloud must be
+// served from the managed encr.app domain, and apps in other clouds
+// must not be served from localhost. Local configs are not checked.
+func WithCloudURLCheck() ParseOption {
+	return func(o *parseOptions) {
+		o.cloudURLCheck = true
+	}
+}
+
+// WithExpectedDatabases checks that the config contains exactly the SQL
+// databases with the given Encore names, rejecting configs with databases
+// that aren't expected or missing ones that are. With no names, it checks
+// that the config contains no SQL databases at all.
+func WithExpectedDatabases(names ...string) ParseOption {
+	return func(o *parseOptions) {
+		o.expectedDatabases = append([]string{}, names...)
+	}
+}
+
+// WithDefaults fills in unset fields using [Runtime.ApplyDefaults].
+func WithDefaults() ParseOption {
+	return func(o *parseOptions) {
+		o.defaults = true
+	}
+}
+
+// WithEnvLookup expands ${VAR} and $VAR references in the hosts and
+// credentials of the SQL and Redis servers, resolving each variable
+// using lookup. It is an error for a referenced variable to be missing.
+func WithEnvLookup(lookup func(string) (string, bool)) ParseOption {
+	return func(o *parseOptions) {
+		o.lookup = lookup
+	}
+}
+
+// WithSQLHostMapping rewrites the hosts of the SQL servers in the config,
+// such as to connect to remote databases through an SSH tunnel. Each server
+// is looked up in mapping by its ID, the index into [Runtime.SQLServers]
+// such as "0", and then by its host, such as "db.example.com:5432";
+// servers matching neither are left unchanged. Hosts are rewritten after
+// environment variables are expanded using [WithEnvLookup].
+func WithSQLHostMapping(mapping map[string]string) ParseOption {
+	return func(o *parseOptions) {
+		o.sqlHosts = maps.Clone(mapping)
+	}
+}
+
+// WithSecretResolver resolves secret fields of the config, such as database
+// passwords and auth keys, whose value is of the form "secret://<ref>":
+// the value is replaced by the result of calling resolve with ref.
+// Secret fields with other values are left as-is.
+//
+// It is an error for resolve to fail, and the error names the ref.
+func WithSecretResolver(resolve func(ref string) (string, error)) ParseOption {
+	return func(o *parseOptions) {
+		o.resolveSecret = resolve
+	}
+}
+
+// WithDecrypt sets a function to decrypt the decoded config before it is
+// unmarshaled, for configs that are delivered encrypted.
+// See [ParseRuntimeWith].
+func WithDecrypt(decrypt func([]byte) ([]byte, error)) ParseOption {
+	return func(o *parseOptions) {
+		o.decrypt = decrypt
+	}
+}
+
+// WithRedisDatabases sets the number of databases each Redis server has,
+// which bounds the database indices accepted by [WithValidate].
+// It defaults to 16, matching the Redis default.
+func WithRedisDatabases(n int) ParseOption {
+	return func(o *parseOptions) {
+		o.validation.redisDatabases = n
+	}
+}
+
+// WithDeprecationHandler sets a function to call with the name of each
+// populated field in the config that is deprecated, such as to log a warning.
+// Deprecated fields are still parsed and used as before.
+func WithDeprecationHandler(handler func(field string)) ParseOption {
+	return func(o *parseOptions) {
+		o.deprecated = handler
+	}
+}
+
+// WithLogger logs a structured record to logger when parsing fails,
+// with the attributes "stage", "error" and "config_length".
+// The config itself is never logged.
+func WithLogger(logger *slog.Logger) ParseOption {
+	return func(o *parseOptions) {
+		o.logger = logger
+	}
+}
+
+// withParseInfo fills in info with how the config was encoded.
+// It's unexported since it's only used by [ParseRuntimeInfo].
+func withParseInfo(info *ParseInfo) ParseOption {
+	return func(o *parseOptions) {
+		o.info = info
+	}
+}
+
+// defaultMaxConfigBytes is the default limit on the size
+// of a decoded config, set using [WithMaxConfigBytes].
+const defaultMaxConfigBytes = 64 << 20
+
+// WithMaxConfigBytes limits the size of the config to n bytes,
+// both after decoding it and after decompressing it, so that a corrupt
+// or malicious config can't cause large allocations. Larger configs are
+// rejected. If n is not positive, the default limit of 64 MiB is used.
+func WithMaxConfigBytes(n int) ParseOption {
+	return func(o *parseOptions) {
+		if n <= 0 {
+			n = defaultMaxConfigBytes
+		}
+		o.maxConfigBytes = n
+	}
+}
@@ -1,62 +1,518 @@
 package config
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
-	"net/url"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	errNoRuntimeConfig = errors.New("no encore runtime config provided")
+	errNoStaticConfig  = errors.New("no encore static config provided")
 )
 
 // ParseRuntime parses the Encore runtime config.
+//
+// It terminates the process if the config cannot be parsed.
+// Use [ParseRuntimeErr] to handle the error instead.
+//
+// Unlike [ParseRuntimeErr] it doesn't check the API base URL, so that
+// apps whose config has an empty or unusual one keep starting as before.
 func ParseRuntime(config, deployID string) *Runtime {
-	if config == "" {
-		log.Fatalln("encore runtime: fatal error: no encore runtime config provided")
+	return MustParseRuntime(config, WithDeployID(deployID))
+}
+
+// MustParseRuntime is like [ParseRuntimeOptions] but terminates the
+// process if the config cannot be parsed, like [ParseRuntime].
+//
+// If a logger is set using [WithLogger] the failure is logged as a
+// structured record before exiting. As with [ParseRuntime], the API base
+// URL is only checked if [WithValidate] is used.
+func MustParseRuntime(config string, opts ...ParseOption) *Runtime {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	// We used to support RawURLEncoding, but now we use StdEncoding.
-	// Try both if StdEncoding fails.
-	var (
-		bytes []byte
-		err   error
+	opts = append(opts[:len(opts):len(opts)], func(o *parseOptions) { o.skipAPIBaseURLCheck = true })
+	cfg, err := ParseRuntimeOptions(config, opts...)
+	if err != nil {
+		if o.logger == nil {
+			log.Fatalln("encore runtime: fatal error:", err)
+		}
+		// The error has already been logged by ParseRuntimeOptions.
+		exit(1)
+	}
+	return cfg
+}
+
+// exit is os.Exit, overridden in tests.
+var exit = os.Exit
+
+// logParseError logs err, which occurred parsing config, to logger.
+// The config itself is never logged since it contains secrets.
+func logParseError(logger *slog.Logger, config string, err error) {
+	stage := Stage("")
+	if perr, ok := err.(*ParseError); ok {
+		stage = perr.Stage
+	}
+	logger.Error("could not parse encore runtime config",
+		slog.String("stage", string(stage)),
+		slog.String("error", err.Error()),
+		slog.Int("config_length", len(config)),
 	)
-	// nosemgrep
-	if bytes, err = base64.StdEncoding.DecodeString(config); err != nil {
-		bytes, err = base64.RawURLEncoding.DecodeString(config)
+}
+
+// ParseRuntimeErr is like [ParseRuntime] but returns an error
+// instead of terminating the process.
+//
+// The returned error is a *[ParseError] describing the stage at which
+// parsing failed, and wraps the underlying cause. Unlike [ParseRuntime],
+// it rejects an API base URL that isn't an http or https URL with a host.
+func ParseRuntimeErr(config, deployID string) (*Runtime, error) {
+	return ParseRuntimeOptions(config, WithDeployID(deployID))
+}
+
+// ParseRuntimeOptions parses the Encore runtime config,
+// configured by the given options.
+//
+// With no options it is equivalent to [ParseRuntimeErr] with an empty deploy ID.
+// The options compose; for example [WithEnvLookup] and [WithValidate] together
+// validate the config after environment variables have been expanded.
+func ParseRuntimeOptions(config string, opts ...ParseOption) (*Runtime, error) {
+	return parseRuntime(context.Background(), config, opts...)
+}
+
+// ParseRuntimeStrict is like [ParseRuntimeErr] but rejects configs
+// containing fields or a cloud this version of the runtime doesn't know about.
+// It is equivalent to using [ParseRuntimeOptions] with [WithStrict].
+func ParseRuntimeStrict(config, deployID string) (*Runtime, error) {
+	return ParseRuntimeOptions(config, WithDeployID(deployID), WithStrict())
+}
+
+// ParseRuntimeValidated is like [ParseRuntimeErr] but additionally
+// normalizes the CORS config using [CORS.Normalize] and checks the
+// parsed config using [Runtime.Validate].
+// It is equivalent to using [ParseRuntimeOptions] with [WithValidate].
+func ParseRuntimeValidated(config, deployID string) (*Runtime, error) {
+	return ParseRuntimeOptions(config, WithDeployID(deployID), WithValidate())
+}
+
+// ParseRuntimeExpand is like [ParseRuntimeErr] but additionally expands
+// ${VAR} and $VAR references in the hosts and credentials of the SQL and
+// Redis servers, resolving each variable using lookup.
+// It is equivalent to using [ParseRuntimeOptions] with [WithEnvLookup].
+//
+// It is an error for a referenced variable to be missing.
+func ParseRuntimeExpand(config, deployID string, lookup func(string) (string, bool)) (*Runtime, error) {
+	return ParseRuntimeOptions(config, WithDeployID(deployID), WithEnvLookup(lookup))
+}
+
+// ParseRuntimeWithDefaults is like [ParseRuntimeErr] but additionally
+// fills in unset fields using [Runtime.ApplyDefaults].
+// It is equivalent to using [ParseRuntimeOptions] with [WithDefaults].
+func ParseRuntimeWithDefaults(config, deployID string) (*Runtime, error) {
+	return ParseRuntimeOptions(config, WithDeployID(deployID), WithDefaults())
+}
+
+// ParseRuntimeWith is like [ParseRuntimeErr] but additionally passes the
+// decoded config through decrypt before it is unmarshaled, for configs
+// that are delivered encrypted.
+// It is equivalent to using [ParseRuntimeOptions] with [WithDecrypt].
+//
+// If decrypt is nil it behaves exactly like [ParseRuntimeErr].
+func ParseRuntimeWith(config, deployID string, decrypt func([]byte) ([]byte, error)) (*Runtime, error) {
+	return ParseRuntimeOptions(config, WithDeployID(deployID), WithDecrypt(decrypt))
+}
+
+// ParseRuntimeContext is like [ParseRuntimeOptions] but stops early if ctx
+// is canceled, such as when a decrypt hook set with [WithDecrypt]
+// takes too long. In that case the returned *[ParseError] wraps ctx.Err().
+func ParseRuntimeContext(ctx context.Context, config, deployID string, opts ...ParseOption) (*Runtime, error) {
+	opts = append([]ParseOption{WithDeployID(deployID)}, opts...)
+	return parseRuntime(ctx, config, opts...)
+}
+
+// ParseInfo describes how a runtime config was encoded.
+// It's meant for diagnosing configs that fail to parse.
+type ParseInfo struct {
+	// Encoding is the encoding the config was decoded with:
+	// "base64", "base64-url", "base64-raw", "base64-raw-url" or "ascii85".
+	Encoding string
+
+	// Compressed reports whether the config was gzip-compressed.
+	Compressed bool
+
+	// DecodedBytes is the size of the config after decoding it,
+	// before it is decrypted or decompressed.
+	DecodedBytes int
+}
+
+// ParseRuntimeInfo is like [ParseRuntimeErr] but also reports how the
+// config was encoded. The info is filled in as far as parsing got, so
+// it can be inspected even if an error is returned.
+func ParseRuntimeInfo(config, deployID string) (*Runtime, ParseInfo, error) {
+	var info ParseInfo
+	cfg, err := ParseRuntimeOptions(config, WithDeployID(deployID), withParseInfo(&info))
+	return cfg, info, err
+}
+
+func parseRuntime(ctx context.Context, config string, options ...ParseOption) (*Runtime, error) {
+	opts := parseOptions{maxConfigBytes: defaultMaxConfigBytes}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	cfg, err := doParseRuntime(ctx, config, opts)
+	if err != nil && opts.logger != nil {
+		logParseError(opts.logger, config, err)
 	}
+	return cfg, err
+}
+
+func doParseRuntime(ctx context.Context, config string, opts parseOptions) (*Runtime, error) {
+	if cleanConfig(config) == "" {
+		return nil, &ParseError{Stage: StageDecode, Err: errNoRuntimeConfig}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, &ParseError{Stage: StageDecode, Err: err}
+	}
+
+	data, encoding, err := decodeConfigEncoding(config, opts.maxConfigBytes)
 	if err != nil {
-		log.Fatalln("encore runtime: fatal error: could not decode encore runtime config:", err)
+		return nil, &ParseError{Stage: StageDecode, Err: fmt.Errorf("could not decode encore runtime config: %w", err)}
+	}
+	if opts.info != nil {
+		opts.info.Encoding = encoding
+		opts.info.DecodedBytes = len(data)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, &ParseError{Stage: StageDecode, Err: err}
+	}
+	if opts.decrypt != nil {
+		// decrypt may outlive a cancelled ctx, so it mustn't
+		// share the variable its result is assigned to.
+		in := data
+		if data, err = runContext(ctx, func() ([]byte, error) { return opts.decrypt(in) }); err != nil {
+			return nil, &ParseError{Stage: StageDecrypt, Err: fmt.Errorf("could not decrypt encore runtime config: %w", err)}
+		}
+	}
+	if opts.info != nil {
+		opts.info.Compressed = isCompressedConfig(data)
+	}
+	if data, err = unwrapConfig(data, opts.maxConfigBytes); err != nil {
+		return nil, &ParseError{Stage: StageDecode, Err: fmt.Errorf("could not unpack encore runtime config: %w", err)}
+	}
+	if opts.relaxed {
+		data = relaxJSON(data)
+	}
+
+	if opts.strict || opts.validate {
+		if err := errors.Join(duplicateKeyErrors(data, opts.strict)...); err != nil {
+			return nil, &ParseError{Stage: StageValidate, Err: fmt.Errorf("invalid encore runtime config: %w", err)}
+		}
 	}
 
 	var cfg Runtime
-	if err := json.Unmarshal(bytes, &cfg); err != nil {
-		log.Fatalln("encore runtime: fatal error: could not parse encore runtime config:", err)
+	if err := unmarshalRuntime(data, &cfg, opts.strict); err != nil {
+		return nil, &ParseError{Stage: StageUnmarshal, Err: fmt.Errorf("could not parse encore runtime config: %w", err)}
+	}
+	if opts.extraFields && !opts.strict {
+		if cfg.Extra, err = extraFields(data); err != nil {
+			return nil, &ParseError{Stage: StageUnmarshal, Err: fmt.Errorf("could not parse encore runtime config: %w", err)}
+		}
+	}
+	if err := migrateRuntime(&cfg, CurrentSchemaVersion); err != nil {
+		return nil, &ParseError{Stage: StageUnmarshal, Err: fmt.Errorf("could not parse encore runtime config: %w", err)}
+	}
+
+	if opts.deprecated != nil {
+		reportDeprecated(&cfg, opts.deprecated)
+	}
+
+	if opts.strict {
+		if err := errors.Join(cfg.validatePubsubProviders()...); err != nil {
+			return nil, &ParseError{Stage: StageValidate, Err: fmt.Errorf("invalid encore runtime config: %w", err)}
+		}
+	}
+
+	if cfg.EnvCloud != "" && !IsValidEnvCloud(cfg.EnvCloud) {
+		if opts.cloudFallback != "" {
+			if opts.deprecated != nil {
+				opts.deprecated("env_cloud")
+			}
+			cfg.EnvCloud = opts.cloudFallback
+		} else if opts.strict {
+			return nil, &ParseError{Stage: StageValidate, Err: fmt.Errorf("invalid encore runtime config: %w", errors.Join(cfg.validateEnv()...))}
+		}
 	}
 
-	if _, err := url.Parse(cfg.APIBaseURL); err != nil {
-		log.Fatalln("encore runtime: fatal error: could not parse api base url from encore runtime config:", err)
+	if opts.lookup != nil {
+		if err := cfg.expandEnv(opts.lookup); err != nil {
+			return nil, &ParseError{Stage: StageExpand, Err: fmt.Errorf("could not expand encore runtime config: %w", err)}
+		}
+	}
+
+	if opts.sqlHosts != nil {
+		cfg.mapSQLHosts(opts.sqlHosts)
+	}
+
+	if opts.resolveSecret != nil {
+		if err := cfg.resolveSecrets(opts.resolveSecret); err != nil {
+			return nil, &ParseError{Stage: StageResolve, Err: fmt.Errorf("could not resolve encore runtime config secrets: %w", err)}
+		}
+	}
+
+	if opts.defaults {
+		_ = cfg.ApplyDefaults() // cfg isn't frozen yet
+	}
+
+	if opts.apiBaseURL != "" {
+		cfg.APIBaseURL = opts.apiBaseURL
+	}
+	if !opts.skipParseChecks {
+		errs := cfg.validatePubsubTopics()
+		if !opts.skipAPIBaseURLCheck {
+			errs = append(errs, validateAPIBaseURL(cfg.APIBaseURL))
+		}
+		if err := errors.Join(errs...); err != nil {
+			return nil, &ParseError{Stage: StageValidate, Err: fmt.Errorf("invalid encore runtime config: %w", err)}
+		}
+	}
+	if opts.expectedDatabases != nil {
+		if err := errors.Join(cfg.checkExpectedDatabases(opts.expectedDatabases)...); err != nil {
+			return nil, &ParseError{Stage: StageValidate, Err: fmt.Errorf("invalid encore runtime config: %w", err)}
+		}
+	}
+	if opts.cloudURLCheck {
+		if err := cfg.checkCloudURL(); err != nil {
+			return nil, &ParseError{Stage: StageValidate, Err: fmt.Errorf("invalid encore runtime config: %w", err)}
+		}
 	}
 
 	// If the environment deploy ID is set, use that instead of the one
 	// embedded in the runtime config
-	if deployID != "" {
-		cfg.DeployID = deployID
+	deployID := opts.deployID
+	for _, name := range opts.deployIDSources {
+		if strings.TrimSpace(deployID) != "" {
+			break
+		}
+		deployID = getenv(name)
 	}
+	cfg.DeployID = cfg.EffectiveDeployID(deployID)
 
-	return &cfg
+	if opts.validate {
+		errs := append([]error{cfg.CORS.Normalize()}, cfg.validate(opts.validation)...)
+		if err := errors.Join(errs...); err != nil {
+			return nil, &ParseError{Stage: StageValidate, Err: fmt.Errorf("invalid encore runtime config: %w", err)}
+		}
+	}
+
+	return &cfg, nil
+}
+
+// runContext calls fn and returns its result, or returns ctx.Err()
+// as soon as ctx is done if that happens first.
+//
+// If ctx is canceled, fn is left running in the background
+// and its result is discarded.
+func runContext(ctx context.Context, fn func() ([]byte, error)) ([]byte, error) {
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	type result struct {
+		data []byte
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		data, err := fn()
+		ch <- result{data, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		return res.data, res.err
+	}
+}
+
+// unmarshalRuntime unmarshals the JSON-encoded runtime config in data into cfg.
+// If strict is true, unknown fields are reported as errors.
+func unmarshalRuntime(data []byte, cfg *Runtime, strict bool) error {
+	if !strict {
+		return json.Unmarshal(data, cfg)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after top-level value")
+	}
+	return nil
+}
+
+// ParseRuntimeReader is like [ParseRuntimeErr] but reads the
+// base64-encoded config from r.
+func ParseRuntimeReader(r io.Reader, deployID string) (*Runtime, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read encore runtime config: %w", err)
+	}
+	return ParseRuntimeErr(string(data), deployID)
+}
+
+// stdin is the reader used by ParseRuntimeStdin, overridden in tests.
+var stdin io.Reader = os.Stdin
+
+// ParseRuntimeStdin is like [ParseRuntimeReader] but reads the config
+// from standard input.
+//
+// If standard input is a terminal it reports that no config was provided
+// rather than waiting for input.
+func ParseRuntimeStdin(deployID string) (*Runtime, error) {
+	if f, ok := stdin.(*os.File); ok {
+		if fi, err := f.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			return nil, &ParseError{Stage: StageDecode, Err: errNoRuntimeConfig}
+		}
+	}
+	return ParseRuntimeReader(stdin, deployID)
+}
+
+// ParseRuntimeFile is like [ParseRuntimeErr] but reads the
+// base64-encoded config from the file at path.
+//
+// Trailing whitespace, such as a newline added by an editor, is ignored.
+func ParseRuntimeFile(path, deployID string) (*Runtime, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read encore runtime config: %w", err)
+	}
+	config := strings.TrimRight(string(data), " \t\r\n")
+	return ParseRuntimeErr(config, deployID)
+}
+
+// ParseRuntimeChunks is like [ParseRuntimeErr] but parses a config that
+// has been split into multiple chunks, such as when a platform limits the
+// length of a single environment variable. The chunks are concatenated
+// in order before decoding.
+//
+// Each chunk must be non-empty; an empty chunk is reported as missing.
+// If the joined config can't be decoded because the chunks use different
+// base64 alphabets or padding, the error names the chunk that differs.
+func ParseRuntimeChunks(chunks []string, deployID string) (*Runtime, error) {
+	if len(chunks) == 0 {
+		return nil, &ParseError{Stage: StageDecode, Err: errNoRuntimeConfig}
+	}
+	for i, chunk := range chunks {
+		if chunk == "" {
+			return nil, &ParseError{Stage: StageDecode, Err: fmt.Errorf("encore runtime config chunk %d is missing", i)}
+		}
+	}
+	cfg, err := ParseRuntimeErr(strings.Join(chunks, ""), deployID)
+
+	// Chunks encoded with different base64 variants only fail once joined,
+	// with an error about the config as a whole; point at the odd chunk out.
+	var perr *ParseError
+	if err != nil && len(chunks) > 1 && errors.As(err, &perr) && perr.Stage == StageDecode {
+		if mixErr := mixedChunkEncodings(chunks); mixErr != nil {
+			return nil, &ParseError{Stage: StageDecode, Err: fmt.Errorf("could not decode encore runtime config: %w", mixErr)}
+		}
+	}
+	return cfg, err
+}
+
+// RuntimeChunksFromEnv collects the chunks of a runtime config from
+// environment variables named <prefix>_0, <prefix>_1, etc,
+// given environ in the form returned by [os.Environ].
+//
+// The result has one element per index up to the highest one found,
+// and missing indices are left empty so that [ParseRuntimeChunks]
+// reports them.
+func RuntimeChunksFromEnv(environ []string, prefix string) []string {
+	var chunks []string
+	for _, kv := range environ {
+		key, val, _ := strings.Cut(kv, "=")
+		suffix, ok := strings.CutPrefix(key, prefix+"_")
+		if !ok {
+			continue
+		}
+		idx, err := strconv.Atoi(suffix)
+		if err != nil || idx < 0 {
+			continue
+		}
+		for len(chunks) <= idx {
+			chunks = append(chunks, "")
+		}
+		chunks[idx] = val
+	}
+	return chunks
+}
+
+// runtimeEnvelope is the JSON envelope some platform integrations
+// wrap the runtime config in.
+type runtimeEnvelope struct {
+	// EncoreRuntime is the encoded runtime config.
+	// It's a pointer to distinguish a missing key from an empty value.
+	EncoreRuntime *string `json:"encoreRuntime"`
+}
+
+// ParseRuntimeEnvelope is like [ParseRuntimeErr] but parses a runtime
+// config wrapped in a JSON envelope of the form
+//
+//	{"encoreRuntime": "<encoded config>", "meta": {...}}
+//
+// Any other keys in the envelope, such as "meta", are ignored.
+func ParseRuntimeEnvelope(raw []byte, deployID string) (*Runtime, error) {
+	var env runtimeEnvelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, &ParseError{Stage: StageDecode, Err: fmt.Errorf("could not parse encore runtime config envelope: %w", err)}
+	}
+	if env.EncoreRuntime == nil {
+		return nil, &ParseError{Stage: StageDecode, Err: errors.New(`encore runtime config envelope: missing "encoreRuntime" key`)}
+	}
+	return ParseRuntimeErr(*env.EncoreRuntime, deployID)
 }
 
 // ParseStatic parses the Encore static config.
+//
+// It terminates the process if the config cannot be parsed.
+// Use [ParseStaticErr] to handle the error instead.
 func ParseStatic(config string) *Static {
+	cfg, err := ParseStaticErr(config)
+	if err != nil {
+		log.Fatalln("encore runtime: fatal error:", err)
+	}
+	return cfg
+}
+
+// ParseStaticErr is like [ParseStatic] but returns an error
+// instead of terminating the process.
+func ParseStaticErr(config string) (*Static, error) {
 	if config == "" {
-		log.Fatalln("encore runtime: fatal error: no encore static config provided")
+		return nil, &ParseError{Stage: StageDecode, Err: errNoStaticConfig}
 	}
-	bytes, err := base64.StdEncoding.DecodeString(config)
+	data, err := base64.StdEncoding.DecodeString(config)
 	if err != nil {
-		log.Fatalln("encore runtime: fatal error: could not decode encore static config:", err)
+		return nil, &ParseError{Stage: StageDecode, Err: fmt.Errorf("could not decode encore static config: %w", err)}
 	}
 	var cfg Static
-	if err := json.Unmarshal(bytes, &cfg); err != nil {
-		log.Fatalln("encore runtime: fatal error: could not parse encore static config:", err)
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, &ParseError{Stage: StageUnmarshal, Err: fmt.Errorf("could not parse encore static config: %w", err)}
 	}
-	return &cfg
+	return &cfg, nil
 }
@@ -0,0 +1,661 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func encodeJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestParseRuntimeErr(t *testing.T) {
+	cfg, err := ParseRuntimeErr(encodeJSON(t, map[string]any{
+		"app_id":       "app",
+		"api_base_url": "https://example.com",
+		"deploy_id":    "embedded",
+	}), "override")
+	if err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+	if cfg.AppID != "app" || cfg.DeployID != "override" {
+		t.Errorf("got app id %q, deploy id %q", cfg.AppID, cfg.DeployID)
+	}
+
+	if _, err := ParseRuntimeErr("", ""); !errors.Is(err, errNoRuntimeConfig) {
+		t.Errorf("empty config: got %v, want %v", err, errNoRuntimeConfig)
+	}
+
+	var decodeErr base64.CorruptInputError
+	if _, err := ParseRuntimeErr("not base64!", ""); !errors.As(err, &decodeErr) {
+		t.Errorf("bad base64: got %v, want a base64.CorruptInputError", err)
+	}
+
+	_, err = ParseRuntimeErr(`{"app_id":"app","api_base_url":"https://example.com"}`, "")
+	if !errors.Is(err, errRawJSONConfig) {
+		t.Errorf("raw json: got %v, want %v", err, errRawJSONConfig)
+	}
+
+	var syntaxErr *json.SyntaxError
+	bad := base64.StdEncoding.EncodeToString([]byte("{not json"))
+	if _, err := ParseRuntimeErr(bad, ""); !errors.As(err, &syntaxErr) {
+		t.Errorf("bad json: got %v, want a *json.SyntaxError", err)
+	}
+}
+
+func TestParseStaticErr(t *testing.T) {
+	cfg, err := ParseStaticErr(encodeJSON(t, map[string]any{"EncoreCompiler": "v1"}))
+	if err != nil {
+		t.Fatalf("ParseStaticErr: %v", err)
+	}
+	if cfg.EncoreCompiler != "v1" {
+		t.Errorf("got compiler %q, want %q", cfg.EncoreCompiler, "v1")
+	}
+
+	if _, err := ParseStaticErr(""); !errors.Is(err, errNoStaticConfig) {
+		t.Errorf("empty config: got %v, want %v", err, errNoStaticConfig)
+	}
+}
+
+func TestParseRuntimeReader(t *testing.T) {
+	config := encodeJSON(t, map[string]any{"app_id": "app", "api_base_url": "https://example.com"})
+	cfg, err := ParseRuntimeReader(iotest.OneByteReader(strings.NewReader(config)), "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeReader: %v", err)
+	}
+	if cfg.AppID != "app" {
+		t.Errorf("got app id %q, want %q", cfg.AppID, "app")
+	}
+
+	if _, err := ParseRuntimeReader(strings.NewReader(""), ""); !errors.Is(err, errNoRuntimeConfig) {
+		t.Errorf("empty reader: got %v, want %v", err, errNoRuntimeConfig)
+	}
+
+	readErr := errors.New("read failed")
+	if _, err := ParseRuntimeReader(iotest.ErrReader(readErr), ""); !errors.Is(err, readErr) {
+		t.Errorf("failing reader: got %v, want %v", err, readErr)
+	}
+}
+
+func TestParseRuntimeStrict(t *testing.T) {
+	config := encodeJSON(t, map[string]any{
+		"app_id":        "app",
+		"api_base_url":  "https://example.com",
+		"unknown_field": true,
+	})
+
+	if _, err := ParseRuntimeErr(config, ""); err != nil {
+		t.Errorf("lenient: unexpected error: %v", err)
+	}
+
+	_, err := ParseRuntimeStrict(config, "")
+	if err == nil {
+		t.Fatal("strict: expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), `"unknown_field"`) {
+		t.Errorf("strict: error %q does not name the unknown field", err)
+	}
+}
+
+func TestParseRuntimeFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	path := write("config", encodeJSON(t, map[string]any{"app_id": "app", "api_base_url": "https://example.com"})+"\n\n")
+	cfg, err := ParseRuntimeFile(path, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeFile: %v", err)
+	}
+	if cfg.AppID != "app" {
+		t.Errorf("got app id %q, want %q", cfg.AppID, "app")
+	}
+
+	if _, err := ParseRuntimeFile(write("empty", "\n"), ""); !errors.Is(err, errNoRuntimeConfig) {
+		t.Errorf("empty file: got %v, want %v", err, errNoRuntimeConfig)
+	}
+
+	if _, err := ParseRuntimeFile(filepath.Join(dir, "missing"), ""); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: got %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestParseRuntimeChunks(t *testing.T) {
+	config, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	third := len(config) / 3
+	environ := []string{
+		"PATH=/bin",
+		"ENCORE_RUNTIME_CONFIG_2=" + config[2*third:],
+		"ENCORE_RUNTIME_CONFIG_0=" + config[:third],
+		"ENCORE_RUNTIME_CONFIG_1=" + config[third:2*third],
+	}
+	chunks := RuntimeChunksFromEnv(environ, "ENCORE_RUNTIME_CONFIG")
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	got, err := ParseRuntimeChunks(chunks, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeChunks: %v", err)
+	}
+	if !reflect.DeepEqual(got, fullRuntime()) {
+		t.Errorf("chunked config mismatch:\ngot  %+v\nwant %+v", got, fullRuntime())
+	}
+
+	// A single chunk behaves like ParseRuntimeErr.
+	if _, err := ParseRuntimeChunks([]string{config}, ""); err != nil {
+		t.Errorf("single chunk: %v", err)
+	}
+
+	// A gap in the indices is reported.
+	chunks = RuntimeChunksFromEnv([]string{environ[1], environ[2]}, "ENCORE_RUNTIME_CONFIG")
+	if _, err := ParseRuntimeChunks(chunks, ""); err == nil || !strings.Contains(err.Error(), "chunk 1 is missing") {
+		t.Errorf("gap: got %v, want a missing chunk 1 error", err)
+	}
+
+	if _, err := ParseRuntimeChunks(nil, ""); !errors.Is(err, errNoRuntimeConfig) {
+		t.Errorf("no chunks: got %v, want %v", err, errNoRuntimeConfig)
+	}
+}
+
+func TestParseRuntimeWith(t *testing.T) {
+	data, err := json.Marshal(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A simple reversible "encryption" for testing.
+	xor := func(b []byte) []byte {
+		out := make([]byte, len(b))
+		for i := range b {
+			out[i] = b[i] ^ 0x5a
+		}
+		return out
+	}
+	plain := base64.StdEncoding.EncodeToString(data)
+	encrypted := base64.StdEncoding.EncodeToString(xor(data))
+
+	tests := []struct {
+		name      string
+		config    string
+		decrypt   func([]byte) ([]byte, error)
+		wantStage Stage
+	}{
+		{
+			name:   "nil",
+			config: plain,
+		},
+		{
+			name:    "identity",
+			config:  plain,
+			decrypt: func(b []byte) ([]byte, error) { return b, nil },
+		},
+		{
+			name:    "transforming",
+			config:  encrypted,
+			decrypt: func(b []byte) ([]byte, error) { return xor(b), nil },
+		},
+		{
+			name:      "failing",
+			config:    encrypted,
+			decrypt:   func([]byte) ([]byte, error) { return nil, errors.New("bad key") },
+			wantStage: StageDecrypt,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRuntimeWith(tt.config, "", tt.decrypt)
+			if tt.wantStage != "" {
+				var perr *ParseError
+				if !errors.As(err, &perr) || perr.Stage != tt.wantStage {
+					t.Fatalf("got error %v, want a *ParseError at stage %q", err, tt.wantStage)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRuntimeWith: %v", err)
+			}
+			if !reflect.DeepEqual(got, fullRuntime()) {
+				t.Errorf("config mismatch:\ngot  %+v\nwant %+v", got, fullRuntime())
+			}
+		})
+	}
+}
+
+func TestParseRuntimeContext(t *testing.T) {
+	config := encodeJSON(t, map[string]any{"app_id": "app", "api_base_url": "https://example.com"})
+
+	cfg, err := ParseRuntimeContext(context.Background(), config, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeContext: %v", err)
+	}
+	if cfg.AppID != "app" {
+		t.Errorf("got app id %q, want %q", cfg.AppID, "app")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseRuntimeContext(ctx, config, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled context: got %v, want %v", err, context.Canceled)
+	}
+
+	// A decrypt hook that blocks until the test is done must not
+	// prevent the parse from returning once the context is canceled.
+	unblock := make(chan struct{})
+	defer close(unblock)
+	blocking := WithDecrypt(func(data []byte) ([]byte, error) {
+		<-unblock
+		return data, nil
+	})
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = ParseRuntimeContext(ctx, config, "", blocking)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Stage != StageDecrypt {
+		t.Fatalf("blocking decrypt: got %v, want a *ParseError at stage %q", err, StageDecrypt)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("blocking decrypt: got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestParseRuntimeOptions(t *testing.T) {
+	config := encodeJSON(t, map[string]any{
+		"app_id":       "app",
+		"api_base_url": "https://example.com",
+		"deploy_id":    "embedded",
+		"sql_servers":  []any{map[string]any{"host": "${DB_HOST}"}},
+		"sql_databases": []any{
+			map[string]any{"encore_name": "users", "server_id": 0, "password": "$DB_PASSWORD"},
+		},
+	})
+	env := map[string]string{"DB_HOST": "db.internal:5432", "DB_PASSWORD": "secret"}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	// With no options the config is parsed as is.
+	cfg, err := ParseRuntimeOptions(config)
+	if err != nil {
+		t.Fatalf("no options: %v", err)
+	}
+	if cfg.DeployID != "embedded" || cfg.SQLServers[0].Host != "${DB_HOST}" {
+		t.Errorf("no options: got deploy id %q, host %q", cfg.DeployID, cfg.SQLServers[0].Host)
+	}
+
+	cfg, err = ParseRuntimeOptions(config,
+		WithDeployID("override"),
+		WithEnvLookup(lookup),
+		WithDefaults(),
+		WithValidate(),
+		WithStrict(),
+	)
+	if err != nil {
+		t.Fatalf("combined options: %v", err)
+	}
+	if cfg.DeployID != "override" {
+		t.Errorf("got deploy id %q, want %q", cfg.DeployID, "override")
+	}
+	if got := cfg.SQLServers[0].Host; got != "db.internal:5432" {
+		t.Errorf("got sql host %q, want %q", got, "db.internal:5432")
+	}
+	if got := cfg.SQLDatabases[0].Password; got != "secret" {
+		t.Errorf("got sql password %q, want %q", got, "secret")
+	}
+	if cfg.EnvType != "production" {
+		t.Errorf("got env type %q, want defaults to be applied", cfg.EnvType)
+	}
+
+	// Validation runs after expansion, so a dangling server
+	// reference is reported alongside the other options.
+	bad := encodeJSON(t, map[string]any{
+		"api_base_url":  "https://example.com",
+		"sql_databases": []any{map[string]any{"encore_name": "users", "server_id": 1}},
+	})
+	if _, err := ParseRuntimeOptions(bad, WithEnvLookup(lookup)); err != nil {
+		t.Errorf("without validation: unexpected error: %v", err)
+	}
+	if _, err := ParseRuntimeOptions(bad, WithEnvLookup(lookup), WithValidate()); err == nil {
+		t.Error("with validation: expected an error, got nil")
+	}
+
+	// A later option overrides an earlier one.
+	cfg, err = ParseRuntimeOptions(config, WithDeployID("first"), WithDeployID("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DeployID != "second" {
+		t.Errorf("got deploy id %q, want %q", cfg.DeployID, "second")
+	}
+}
+
+func TestParseRuntimeEnvelope(t *testing.T) {
+	config := encodeJSON(t, map[string]any{"app_id": "app", "api_base_url": "https://example.com"})
+	raw, err := json.Marshal(map[string]any{
+		"encoreRuntime": config,
+		"meta":          map[string]any{"region": "us-east-1", "attempt": 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ParseRuntimeEnvelope(raw, "override")
+	if err != nil {
+		t.Fatalf("ParseRuntimeEnvelope: %v", err)
+	}
+	if cfg.AppID != "app" || cfg.DeployID != "override" {
+		t.Errorf("got app id %q, deploy id %q", cfg.AppID, cfg.DeployID)
+	}
+
+	_, err = ParseRuntimeEnvelope([]byte(`{"meta": {}}`), "")
+	if err == nil || !strings.Contains(err.Error(), `missing "encoreRuntime" key`) {
+		t.Errorf("missing key: got %v, want a missing key error", err)
+	}
+
+	// An empty value is passed on and reported as a missing config.
+	if _, err := ParseRuntimeEnvelope([]byte(`{"encoreRuntime": ""}`), ""); !errors.Is(err, errNoRuntimeConfig) {
+		t.Errorf("empty value: got %v, want %v", err, errNoRuntimeConfig)
+	}
+
+	var syntaxErr *json.SyntaxError
+	if _, err := ParseRuntimeEnvelope([]byte(`{not json`), ""); !errors.As(err, &syntaxErr) {
+		t.Errorf("bad envelope: got %v, want a *json.SyntaxError", err)
+	}
+}
+
+func TestWithAPIBaseURL(t *testing.T) {
+	config := encodeJSON(t, map[string]any{"api_base_url": "https://blue.example.com"})
+
+	tests := []struct {
+		name     string
+		override string
+		want     string
+		wantErr  string
+	}{
+		{name: "override_wins", override: "https://green.example.com", want: "https://green.example.com"},
+		{name: "empty_keeps_embedded", override: "", want: "https://blue.example.com"},
+		{name: "invalid_override", override: "green.example.com", wantErr: `api base url "green.example.com": missing scheme`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseRuntimeOptions(config, WithAPIBaseURL(tt.override))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRuntimeOptions: %v", err)
+			}
+			if cfg.APIBaseURL != tt.want {
+				t.Errorf("got api base url %q, want %q", cfg.APIBaseURL, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	// The config is valid JSON containing a secret, but fails validation.
+	config := encodeJSON(t, map[string]any{
+		"api_base_url":  "https://example.com",
+		"sql_databases": []any{map[string]any{"encore_name": "users", "password": "hunter2-secret"}},
+	})
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	if _, err := ParseRuntimeOptions(config, WithValidate(), WithLogger(logger)); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("could not parse log record %q: %v", buf.String(), err)
+	}
+	if record["stage"] != string(StageValidate) {
+		t.Errorf("got stage %v, want %q", record["stage"], StageValidate)
+	}
+	if msg, _ := record["error"].(string); !strings.Contains(msg, "no sql servers") {
+		t.Errorf("got error %v, want the validation error", record["error"])
+	}
+	if record["config_length"] != float64(len(config)) {
+		t.Errorf("got config_length %v, want %d", record["config_length"], len(config))
+	}
+	if strings.Contains(buf.String(), config) || strings.Contains(buf.String(), "hunter2-secret") {
+		t.Errorf("log record leaks the config: %s", buf.String())
+	}
+
+	// Nothing is logged on success, or without a logger.
+	buf.Reset()
+	if _, err := ParseRuntimeOptions(config, WithLogger(logger)); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("unexpected log output on success: %s", buf.String())
+	}
+}
+
+func TestMustParseRuntime(t *testing.T) {
+	defer func(orig func(int)) { exit = orig }(exit)
+	var code int
+	exit = func(c int) { code = c }
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	MustParseRuntime("not base64!", WithLogger(logger))
+	if code != 1 {
+		t.Errorf("got exit code %d, want 1", code)
+	}
+	if !strings.Contains(buf.String(), `"stage":"decode"`) {
+		t.Errorf("log output %q does not contain the stage", buf.String())
+	}
+
+	cfg := MustParseRuntime(encodeJSON(t, map[string]any{"app_id": "app", "api_base_url": "https://example.com"}))
+	if cfg.AppID != "app" {
+		t.Errorf("got app id %q, want %q", cfg.AppID, "app")
+	}
+
+	// The API base URL is only checked by the error-returning
+	// and validating parse functions.
+	code = 0
+	noURL := encodeJSON(t, map[string]any{"app_id": "app"})
+	if cfg := ParseRuntime(noURL, ""); code != 0 || cfg.AppID != "app" {
+		t.Errorf("ParseRuntime without an api base url: got exit code %d", code)
+	}
+	if _, err := ParseRuntimeErr(noURL, ""); err == nil || !strings.Contains(err.Error(), "missing api base url") {
+		t.Errorf("ParseRuntimeErr without an api base url: got %v, want a missing api base url error", err)
+	}
+	MustParseRuntime(noURL, WithValidate(), WithLogger(logger))
+	if code != 1 {
+		t.Errorf("MustParseRuntime with WithValidate: got exit code %d, want 1", code)
+	}
+}
+
+func TestParseRuntimeStdin(t *testing.T) {
+	defer func(orig io.Reader) { stdin = orig }(stdin)
+
+	stdin = strings.NewReader(encodeJSON(t, map[string]any{"app_id": "app", "api_base_url": "https://example.com"}) + "\n")
+	cfg, err := ParseRuntimeStdin("override")
+	if err != nil {
+		t.Fatalf("ParseRuntimeStdin: %v", err)
+	}
+	if cfg.AppID != "app" || cfg.DeployID != "override" {
+		t.Errorf("got app id %q, deploy id %q", cfg.AppID, cfg.DeployID)
+	}
+
+	for _, input := range []string{"", "\n", " \r\n"} {
+		stdin = strings.NewReader(input)
+		if _, err := ParseRuntimeStdin(""); !errors.Is(err, errNoRuntimeConfig) {
+			t.Errorf("stdin %q: got %v, want %v", input, err, errNoRuntimeConfig)
+		}
+	}
+}
+
+func TestWithMaxConfigBytes(t *testing.T) {
+	cfg := &Runtime{APIBaseURL: "https://example.com", AppSlug: strings.Repeat("a", 4096)}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := len(data)
+
+	plain, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := EncodeRuntimeCompressed(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ascii, err := EncodeRuntimeASCII85(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compressed) >= size {
+		t.Fatalf("compressed config is %d bytes, want it smaller than %d", len(compressed), size)
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		limit   int
+		wantErr bool
+	}{
+		{name: "base64_at_limit", config: plain, limit: size},
+		{name: "base64_over_limit", config: plain, limit: size - 1, wantErr: true},
+		{name: "gzip_at_limit", config: compressed, limit: size},
+		{name: "gzip_over_limit", config: compressed, limit: size - 1, wantErr: true},
+		{name: "ascii85_at_limit", config: ascii, limit: size},
+		{name: "ascii85_over_limit", config: ascii, limit: size - 1, wantErr: true},
+		{name: "default_limit", config: compressed, limit: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRuntimeOptions(tt.config, WithMaxConfigBytes(tt.limit))
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, errConfigTooLarge) {
+				t.Errorf("got %v, want %v", err, errConfigTooLarge)
+			}
+		})
+	}
+}
+
+func TestWithMaxConfigBytesGzipBomb(t *testing.T) {
+	// A small config that decompresses to far more than the limit.
+	const limit = 1 << 20
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := make([]byte, 1<<20)
+	for written := 0; written <= 16*limit; written += len(chunk) {
+		if _, err := zw.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ParseRuntimeOptions(base64.StdEncoding.EncodeToString(buf.Bytes()), WithMaxConfigBytes(limit))
+	if !errors.Is(err, errConfigTooLarge) {
+		t.Errorf("got %v, want %v", err, errConfigTooLarge)
+	}
+}
+
+func TestParseRuntimeInfo(t *testing.T) {
+	// Runs of '?' and '~' encode to '/' and '+' in base64, which tells
+	// the URL variants apart, and a length that isn't a multiple of 3
+	// tells the padded variants apart.
+	cfg := &Runtime{APIBaseURL: "https://example.com", AppSlug: "??????~~~~~~"}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data)%3 == 0 {
+		cfg.AppSlug += "a"
+		if data, err = json.Marshal(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	compressed, err := EncodeRuntimeCompressed(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ascii, err := EncodeRuntimeASCII85(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		config         string
+		wantEncoding   string
+		wantCompressed bool
+	}{
+		{name: "std", config: base64.StdEncoding.EncodeToString(data), wantEncoding: "base64"},
+		{name: "url", config: base64.URLEncoding.EncodeToString(data), wantEncoding: "base64-url"},
+		{name: "raw_std", config: base64.RawStdEncoding.EncodeToString(data), wantEncoding: "base64-raw"},
+		{name: "raw_url", config: base64.RawURLEncoding.EncodeToString(data), wantEncoding: "base64-raw-url"},
+		{name: "ascii85", config: ascii, wantEncoding: "ascii85"},
+		{name: "compressed", config: compressed, wantEncoding: "base64", wantCompressed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, info, err := ParseRuntimeInfo(tt.config, "")
+			if err != nil {
+				t.Fatalf("ParseRuntimeInfo: %v", err)
+			}
+			if got.AppSlug != cfg.AppSlug {
+				t.Errorf("got app slug %q, want %q", got.AppSlug, cfg.AppSlug)
+			}
+			if info.Encoding != tt.wantEncoding || info.Compressed != tt.wantCompressed {
+				t.Errorf("got info %+v, want encoding %q, compressed %v", info, tt.wantEncoding, tt.wantCompressed)
+			}
+			if !tt.wantCompressed && info.DecodedBytes != len(data) {
+				t.Errorf("got %d decoded bytes, want %d", info.DecodedBytes, len(data))
+			}
+		})
+	}
+
+	// The info is reported as far as parsing got.
+	bad := base64.StdEncoding.EncodeToString([]byte("{not json"))
+	if _, info, err := ParseRuntimeInfo(bad, ""); err == nil || info.Encoding != "base64" || info.DecodedBytes != 9 {
+		t.Errorf("bad json: got info %+v, err %v", info, err)
+	}
+}
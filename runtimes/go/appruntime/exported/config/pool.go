@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// The connection pool settings used for SQL databases
+// that don't configure them.
+const (
+	defaultMaxOpenConns    = 30
+	defaultConnMaxLifetime = time.Hour
+)
+
+// PoolConfig describes the connection pool of a SQL database.
+type PoolConfig struct {
+	MaxOpenConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// PoolConfig returns the connection pool settings for the database,
+// with the defaults filled in for any that are unset.
+func (d *SQLDatabase) PoolConfig() PoolConfig {
+	lifetime := d.ConnMaxLifetime.Std()
+	if lifetime <= 0 {
+		lifetime = defaultConnMaxLifetime
+	}
+	return PoolConfig{
+		MaxOpenConns:    d.maxOpenConns(),
+		ConnMaxLifetime: lifetime,
+	}
+}
+
+// maxOpenConns returns the maximum number of open connections,
+// falling back to the default if MaxConnections is unset.
+func (d *SQLDatabase) maxOpenConns() int {
+	if d.MaxConnections > 0 {
+		return d.MaxConnections
+	}
+	return defaultMaxOpenConns
+}
+
+// validatePool checks that the pool settings are not negative.
+func (d *SQLDatabase) validatePool() []error {
+	var errs []error
+	if d.MaxConnections < 0 {
+		errs = append(errs, fmt.Errorf("sql database %q: max connections %d: must not be negative", d.EncoreName, d.MaxConnections))
+	}
+	if d.ConnMaxLifetime < 0 {
+		errs = append(errs, fmt.Errorf("sql database %q: conn max lifetime %v: must not be negative", d.EncoreName, d.ConnMaxLifetime))
+	}
+	return errs
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoolConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		db   SQLDatabase
+		want PoolConfig
+	}{
+		{
+			name: "defaults",
+			want: PoolConfig{MaxOpenConns: 30, ConnMaxLifetime: time.Hour},
+		},
+		{
+			name: "legacy_fields",
+			db:   SQLDatabase{MinConnections: 5, MaxConnections: 10},
+			want: PoolConfig{MaxOpenConns: 10, ConnMaxLifetime: time.Hour},
+		},
+		{
+			name: "explicit",
+			db: SQLDatabase{
+				MaxConnections:  20,
+				ConnMaxLifetime: Duration(5 * time.Minute),
+			},
+			want: PoolConfig{MaxOpenConns: 20, ConnMaxLifetime: 5 * time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.db.PoolConfig(); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePool(t *testing.T) {
+	tests := []struct {
+		name    string
+		db      SQLDatabase
+		wantErr string
+	}{
+		{name: "valid", db: SQLDatabase{MaxConnections: 10, ConnMaxLifetime: Duration(time.Minute)}},
+		{name: "unset"},
+		{
+			name:    "negative_lifetime",
+			db:      SQLDatabase{EncoreName: "db", ConnMaxLifetime: Duration(-time.Second)},
+			wantErr: `sql database "db": conn max lifetime -1s: must not be negative`,
+		},
+		{
+			name:    "negative_max_connections",
+			db:      SQLDatabase{EncoreName: "db", MaxConnections: -1},
+			wantErr: "max connections -1: must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.db.validatePool()
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.wantErr) {
+				t.Errorf("got %v, want an error containing %q", errs, tt.wantErr)
+			}
+		})
+	}
+}
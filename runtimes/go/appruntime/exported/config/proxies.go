@@ -0,0 +1,30 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// TrustedProxyNets parses the CIDR ranges in r.TrustedProxies.
+// It reports every entry that is not a valid CIDR range,
+// and returns nil if there are no trusted proxies.
+func (r *Runtime) TrustedProxyNets() ([]*net.IPNet, error) {
+	var (
+		nets []*net.IPNet
+		errs []error
+	)
+	for _, cidr := range r.TrustedProxies {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("trusted proxy %q: invalid CIDR range", cidr))
+			continue
+		}
+		nets = append(nets, ipNet)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return nets, nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrustedProxyNets(t *testing.T) {
+	cfg := &Runtime{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.0/24", "fd00::/8", " 2001:db8::/32 "}}
+	nets, err := cfg.TrustedProxyNets()
+	if err != nil {
+		t.Fatalf("TrustedProxyNets: %v", err)
+	}
+	var got []string
+	for _, n := range nets {
+		got = append(got, n.String())
+	}
+	if want := "10.0.0.0/8 192.168.1.0/24 fd00::/8 2001:db8::/32"; strings.Join(got, " ") != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	cfg.TrustedProxies = []string{"10.0.0.0/8", "10.0.0.1", "300.0.0.0/8"}
+	if _, err := cfg.TrustedProxyNets(); err == nil {
+		t.Fatal("malformed: expected an error, got nil")
+	} else {
+		for _, want := range []string{`trusted proxy "10.0.0.1": invalid CIDR range`, `trusted proxy "300.0.0.0/8"`} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q does not contain %q", err, want)
+			}
+		}
+		if strings.Contains(err.Error(), `"10.0.0.0/8"`) {
+			t.Errorf("error %q names a valid entry", err)
+		}
+	}
+	if errs := cfg.validate(validateOptions{}); !strings.Contains(joinErrs(errs), "trusted proxy") {
+		t.Errorf("validate: got %v, want a trusted proxy error", errs)
+	}
+
+	if nets, err := (&Runtime{}).TrustedProxyNets(); nets != nil || err != nil {
+		t.Errorf("unset: got %v, %v, want nil, nil", nets, err)
+	}
+}
+
+func joinErrs(errs []error) string {
+	var s []string
+	for _, err := range errs {
+		s = append(s, err.Error())
+	}
+	return strings.Join(s, "\n")
+}
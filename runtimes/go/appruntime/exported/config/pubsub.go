@@ -0,0 +1,123 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// ProviderKind is the kind of a pubsub provider.
+type ProviderKind string
+
+const (
+	ProviderNSQ         ProviderKind = "nsq"
+	ProviderGCP         ProviderKind = "gcp"
+	ProviderAWS         ProviderKind = "aws"
+	ProviderAzure       ProviderKind = "azure"
+	ProviderEncoreCloud ProviderKind = "encore_cloud"
+)
+
+// Kind returns the kind of the provider, based on which of its
+// provider-specific configs is set. It returns "" if none are,
+// or if more than one is.
+func (p *PubsubProvider) Kind() ProviderKind {
+	var kind ProviderKind
+	for _, k := range []struct {
+		kind ProviderKind
+		set  bool
+	}{
+		{ProviderNSQ, p.NSQ != nil},
+		{ProviderGCP, p.GCP != nil},
+		{ProviderAWS, p.AWS != nil},
+		{ProviderAzure, p.Azure != nil},
+		{ProviderEncoreCloud, p.EncoreCloud != nil},
+	} {
+		if !k.set {
+			continue
+		} else if kind != "" {
+			return ""
+		}
+		kind = k.kind
+	}
+	return kind
+}
+
+// PubsubProvider returns the first pubsub provider of the given kind.
+func (r *Runtime) PubsubProvider(kind ProviderKind) (*PubsubProvider, bool) {
+	for _, p := range r.PubsubProviders {
+		if p != nil && p.Kind() == kind {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// validatePubsubProviders checks that each pubsub provider is of exactly
+// one known kind. It's only checked in strict mode, since configs from
+// newer versions of Encore may contain providers this runtime doesn't know.
+func (r *Runtime) validatePubsubProviders() []error {
+	var errs []error
+	for i, p := range r.PubsubProviders {
+		if p == nil || p.Kind() == "" {
+			errs = append(errs, fmt.Errorf("pubsub provider %d: unknown or ambiguous provider kind", i))
+		}
+	}
+	return errs
+}
+
+// Subscriptions returns the subscriptions to the topic with the given
+// Encore name, sorted by their Encore name. It returns nil if the
+// topic doesn't exist.
+func (r *Runtime) Subscriptions(topic string) []*PubsubSubscription {
+	t := r.PubsubTopics[topic]
+	if t == nil {
+		return nil
+	}
+	subs := make([]*PubsubSubscription, 0, len(t.Subscriptions))
+	for _, sub := range t.Subscriptions {
+		if sub != nil {
+			subs = append(subs, sub)
+		}
+	}
+	slices.SortFunc(subs, func(a, b *PubsubSubscription) int {
+		return cmp.Compare(a.EncoreName, b.EncoreName)
+	})
+	return subs
+}
+
+// validatePubsubSubscriptions checks that the subscriptions that name
+// their topic name a declared topic, and the one they're listed under.
+func (r *Runtime) validatePubsubSubscriptions() []error {
+	keys := make([]string, 0, len(r.PubsubTopics))
+	for key := range r.PubsubTopics {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var errs []error
+	for _, key := range keys {
+		topic := r.PubsubTopics[key]
+		if topic == nil {
+			continue
+		}
+		subKeys := make([]string, 0, len(topic.Subscriptions))
+		for subKey := range topic.Subscriptions {
+			subKeys = append(subKeys, subKey)
+		}
+		slices.Sort(subKeys)
+
+		for _, subKey := range subKeys {
+			sub := topic.Subscriptions[subKey]
+			if sub == nil || sub.Topic == "" || sub.Topic == key {
+				continue
+			}
+			if _, ok := r.PubsubTopics[sub.Topic]; !ok {
+				errs = append(errs, fmt.Errorf("pubsub subscription %q: unknown topic %q", subKey, sub.Topic))
+			} else {
+				errs = append(errs, fmt.Errorf("pubsub subscription %q: references topic %q but is listed under topic %q",
+					subKey, sub.Topic, key))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPubsubProviderKind(t *testing.T) {
+	tests := []struct {
+		provider *PubsubProvider
+		want     ProviderKind
+	}{
+		{&PubsubProvider{NSQ: &NSQProvider{}}, ProviderNSQ},
+		{&PubsubProvider{GCP: &GCPPubsubProvider{}}, ProviderGCP},
+		{&PubsubProvider{AWS: &AWSPubsubProvider{}}, ProviderAWS},
+		{&PubsubProvider{Azure: &AzureServiceBusProvider{}}, ProviderAzure},
+		{&PubsubProvider{EncoreCloud: &EncoreCloudPubsubProvider{}}, ProviderEncoreCloud},
+		{&PubsubProvider{}, ""},
+		{&PubsubProvider{GCP: &GCPPubsubProvider{}, AWS: &AWSPubsubProvider{}}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.provider.Kind(); got != tt.want {
+			t.Errorf("Kind() of %+v = %q, want %q", tt.provider, got, tt.want)
+		}
+	}
+}
+
+func TestRuntimePubsubProvider(t *testing.T) {
+	cfg := &Runtime{PubsubProviders: []*PubsubProvider{
+		{GCP: &GCPPubsubProvider{}},
+		{AWS: &AWSPubsubProvider{}},
+	}}
+	if p, ok := cfg.PubsubProvider(ProviderAWS); !ok || p != cfg.PubsubProviders[1] {
+		t.Errorf("PubsubProvider(aws) = %+v, %v", p, ok)
+	}
+	if p, ok := cfg.PubsubProvider(ProviderGCP); !ok || p != cfg.PubsubProviders[0] {
+		t.Errorf("PubsubProvider(gcp) = %+v, %v", p, ok)
+	}
+	if _, ok := cfg.PubsubProvider(ProviderNSQ); ok {
+		t.Error("PubsubProvider(nsq): got ok, want not found")
+	}
+}
+
+func TestParseRuntimeUnknownProviderKind(t *testing.T) {
+	config := encodeJSON(t, map[string]any{
+		"api_base_url":     "https://example.com",
+		"pubsub_providers": []any{map[string]any{"gcp": map[string]any{}}, map[string]any{}},
+	})
+
+	if _, err := ParseRuntimeErr(config, ""); err != nil {
+		t.Errorf("lenient: unexpected error: %v", err)
+	}
+	_, err := ParseRuntimeStrict(config, "")
+	if err == nil || !strings.Contains(err.Error(), "pubsub provider 1: unknown or ambiguous provider kind") {
+		t.Errorf("strict: got %v, want an unknown provider kind error", err)
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	cfg := fullRuntime()
+	cfg.PubsubTopics["signups"].Subscriptions["audit"] = &PubsubSubscription{EncoreName: "audit"}
+
+	subs := cfg.Subscriptions("signups")
+	if len(subs) != 2 || subs[0].EncoreName != "audit" || subs[1].EncoreName != "send-welcome" {
+		t.Errorf("got %v, want [audit send-welcome]", subs)
+	}
+	if subs := cfg.Subscriptions("missing"); subs != nil {
+		t.Errorf("missing topic: got %v, want nil", subs)
+	}
+}
+
+func TestValidatePubsubSubscriptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Runtime)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Runtime) {},
+		},
+		{
+			name: "no_topic_reference",
+			modify: func(cfg *Runtime) {
+				cfg.PubsubTopics["signups"].Subscriptions["send-welcome"].Topic = ""
+			},
+		},
+		{
+			name: "dangling_topic",
+			modify: func(cfg *Runtime) {
+				cfg.PubsubTopics["signups"].Subscriptions["send-welcome"].Topic = "sign-ups"
+			},
+			wantErr: `pubsub subscription "send-welcome": unknown topic "sign-ups"`,
+		},
+		{
+			name: "other_topic",
+			modify: func(cfg *Runtime) {
+				cfg.PubsubTopics["orders"] = &PubsubTopic{EncoreName: "orders"}
+				cfg.PubsubTopics["signups"].Subscriptions["send-welcome"].Topic = "orders"
+			},
+			wantErr: `pubsub subscription "send-welcome": references topic "orders" but is listed under topic "signups"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fullRuntime()
+			tt.modify(cfg)
+			err := errors.Join(cfg.validatePubsubSubscriptions()...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
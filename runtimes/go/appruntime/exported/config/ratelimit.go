@@ -0,0 +1,41 @@
+package config
+
+import "fmt"
+
+// GatewayRateLimit describes a token bucket limit on the rate of
+// requests a gateway accepts. It is configuration only; see
+// [Gateway.RateLimiting].
+type GatewayRateLimit struct {
+	// RequestsPerSecond is the sustained rate of requests allowed.
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	// Burst is the number of requests allowed above the
+	// sustained rate in a short burst.
+	Burst int `json:"burst"`
+}
+
+// RateLimit returns the rate limit of the gateway.
+// It reports ok=false if the gateway is not rate limited.
+func (g *Gateway) RateLimit() (rps float64, burst int, ok bool) {
+	if g.RateLimiting == nil {
+		return 0, 0, false
+	}
+	return g.RateLimiting.RequestsPerSecond, g.RateLimiting.Burst, true
+}
+
+// validateRateLimit checks that a configured rate limit actually
+// limits requests, since a zero burst or rate would either reject
+// every request or, depending on the limiter, none of them.
+func (g *Gateway) validateRateLimit() []error {
+	rl := g.RateLimiting
+	if rl == nil {
+		return nil
+	}
+	var errs []error
+	if !(rl.RequestsPerSecond > 0) {
+		errs = append(errs, fmt.Errorf("gateway %q: rate limit requests per second %v: must be positive", g.Name, rl.RequestsPerSecond))
+	}
+	if rl.Burst <= 0 {
+		errs = append(errs, fmt.Errorf("gateway %q: rate limit burst %d: must be positive", g.Name, rl.Burst))
+	}
+	return errs
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGatewayRateLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     *GatewayRateLimit
+		wantRPS   float64
+		wantBurst int
+		wantOK    bool
+		wantErr   string
+	}{
+		{name: "absent"},
+		{
+			name:      "valid",
+			limit:     &GatewayRateLimit{RequestsPerSecond: 12.5, Burst: 20},
+			wantRPS:   12.5,
+			wantBurst: 20,
+			wantOK:    true,
+		},
+		{
+			name:    "zero_burst",
+			limit:   &GatewayRateLimit{RequestsPerSecond: 10},
+			wantRPS: 10,
+			wantOK:  true,
+			wantErr: `gateway "api-gateway": rate limit burst 0: must be positive`,
+		},
+		{
+			name:      "negative_rps",
+			limit:     &GatewayRateLimit{RequestsPerSecond: -1, Burst: 5},
+			wantRPS:   -1,
+			wantBurst: 5,
+			wantOK:    true,
+			wantErr:   `gateway "api-gateway": rate limit requests per second -1: must be positive`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := &Gateway{Name: "api-gateway", RateLimiting: tt.limit}
+			rps, burst, ok := gw.RateLimit()
+			if rps != tt.wantRPS || burst != tt.wantBurst || ok != tt.wantOK {
+				t.Errorf("RateLimit() = %v, %v, %v, want %v, %v, %v", rps, burst, ok, tt.wantRPS, tt.wantBurst, tt.wantOK)
+			}
+
+			errs := (&Runtime{Gateways: []Gateway{*gw}}).validateGateways()
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.wantErr) {
+				t.Errorf("got %v, want an error containing %q", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseGatewayRateLimit(t *testing.T) {
+	for _, limit := range []*GatewayRateLimit{
+		{RequestsPerSecond: 100, Burst: 50},
+		{RequestsPerSecond: 100, Burst: 0},
+	} {
+		cfg := fullRuntime()
+		cfg.Gateways[0].RateLimiting = limit
+		got, err := ParseRuntimeValidated(encodeJSON(t, cfg), "")
+		if limit.Burst == 0 {
+			if err == nil || !strings.Contains(err.Error(), "rate limit burst 0: must be positive") {
+				t.Errorf("zero burst: got %v, want a burst error", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rps, burst, ok := got.Gateways[0].RateLimit(); rps != 100 || burst != 50 || !ok {
+			t.Errorf("RateLimit() = %v, %v, %v, want 100, 50, true", rps, burst, ok)
+		}
+	}
+}
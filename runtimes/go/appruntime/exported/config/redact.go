@@ -0,0 +1,34 @@
+package config
+
+import "encoding/json"
+
+// redacted is the placeholder value for redacted secrets.
+const redacted = "[redacted]"
+
+// Redacted returns a copy of the runtime config with all secrets,
+// such as database passwords and auth keys, replaced by "[redacted]".
+// Unknown fields kept in Extra are removed, since they may hold secrets.
+// The receiver is not modified.
+func (r *Runtime) Redacted() *Runtime {
+	cfg := r.Clone()
+
+	// We don't know what unknown fields contain, so drop them.
+	cfg.Extra = nil
+
+	for _, f := range cfg.secretFields() {
+		if f.get() != "" {
+			f.set(redacted)
+		}
+	}
+	return cfg
+}
+
+// String returns a JSON representation of the runtime config,
+// with secrets redacted.
+func (r *Runtime) String() string {
+	data, err := json.Marshal(r.Redacted())
+	if err != nil {
+		return "<invalid runtime config>"
+	}
+	return string(data)
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// secrets are the secret values used in fullRuntime.
+var secrets = []string{
+	"auth-key-secret-data",
+	"ec-key-secret-data",
+	testKeySecret,
+	"sql-password-secret",
+	"redis-password-secret",
+	"datadog-api-key-secret",
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := fullRuntime()
+	data, err := json.Marshal(cfg.Redacted())
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := fmt.Sprint(cfg)
+
+	for _, secret := range secrets {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("redacted config contains secret %q", secret)
+		}
+		if strings.Contains(str, secret) {
+			t.Errorf("String() contains secret %q", secret)
+		}
+	}
+	if !strings.Contains(str, cfg.AppSlug) {
+		t.Errorf("String() is missing non-secret field values: %s", str)
+	}
+
+	// The original must be left untouched.
+	if got := cfg.SQLDatabases[0].Password; got != "sql-password-secret" {
+		t.Errorf("original sql password modified: got %q", got)
+	}
+	if got := string(cfg.AuthKeys[0].Data); got != "auth-key-secret-data" {
+		t.Errorf("original auth key modified: got %q", got)
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func TestRelaxJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", `{"a":1}`, `{"a":1}`},
+		{"line_comment", "{\n// comment\n\"a\": 1 // trailing\n}", "{\n\n\"a\": 1 \n}"},
+		{"comment_at_eof", `{"a":1}// done`, `{"a":1}`},
+		{"trailing_commas", `{"a":[1,2,],"b":{"c":3,},}`, `{"a":[1,2],"b":{"c":3}}`},
+		{"trailing_comma_whitespace", "[1,\n\t]", "[1\n\t]"},
+		{"strings_untouched", `{"url":"https://x.dev//path","s":",}","e":"\"//,]"}`, `{"url":"https://x.dev//path","s":",}","e":"\"//,]"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(relaxJSON([]byte(tt.input))); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRuntimeRelaxedJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name: "comments",
+			config: `{
+				// The app to run.
+				"app_id": "app",
+				"api_base_url": "https://example.com" // the public url
+			}`,
+		},
+		{
+			name:   "trailing_commas",
+			config: `{"app_id": "app", "api_base_url": "https://example.com", "hosted_services": ["svc",],}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := base64.StdEncoding.EncodeToString([]byte(tt.config))
+			cfg, err := ParseRuntimeOptions(config, WithRelaxedJSON())
+			if err != nil {
+				t.Fatalf("ParseRuntimeOptions: %v", err)
+			}
+			if cfg.AppID != "app" || cfg.APIBaseURL != "https://example.com" {
+				t.Errorf("got app id %q and api base url %q", cfg.AppID, cfg.APIBaseURL)
+			}
+			if tt.name == "trailing_commas" && !reflect.DeepEqual(cfg.HostedServices, []string{"svc"}) {
+				t.Errorf("got hosted services %q, want [svc]", cfg.HostedServices)
+			}
+
+			// Without the option the config is rejected.
+			if _, err := ParseRuntimeOptions(config); err == nil {
+				t.Error("strict: expected an error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// RequestTimeout returns the maximum duration of a request,
+// and whether one is configured.
+func (r *Runtime) RequestTimeout() (time.Duration, bool) {
+	if r.Requests == nil || r.Requests.Timeout == nil {
+		return 0, false
+	}
+	return r.Requests.Timeout.Std(), true
+}
+
+// validateRequests checks that the request timeout, if set, is positive.
+func (r *Runtime) validateRequests() error {
+	if d, ok := r.RequestTimeout(); ok && d <= 0 {
+		return fmt.Errorf("request timeout %v: must be positive", d)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	timeout := Duration(30 * time.Second)
+	tests := []struct {
+		name   string
+		cfg    *Runtime
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "unset", cfg: &Runtime{}},
+		{name: "no_timeout", cfg: &Runtime{Requests: &RequestConfig{}}},
+		{name: "set", cfg: &Runtime{Requests: &RequestConfig{Timeout: &timeout}}, want: 30 * time.Second, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.cfg.RequestTimeout()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("got %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRequestTimeoutString(t *testing.T) {
+	config := encodeJSON(t, map[string]any{
+		"api_base_url": "https://example.com",
+		"requests":     map[string]any{"timeout": "1m30s"},
+	})
+	cfg, err := ParseRuntimeErr(config, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+	if got, ok := cfg.RequestTimeout(); got != 90*time.Second || !ok {
+		t.Errorf("got %v, %v, want %v, true", got, ok, 90*time.Second)
+	}
+}
+
+func TestValidateRequests(t *testing.T) {
+	zero, negative, positive := Duration(0), Duration(-time.Second), Duration(time.Second)
+	for _, tt := range []struct {
+		timeout *Duration
+		wantErr bool
+	}{
+		{nil, false},
+		{&positive, false},
+		{&zero, true},
+		{&negative, true},
+	} {
+		cfg := &Runtime{Requests: &RequestConfig{Timeout: tt.timeout}}
+		if err := cfg.validateRequests(); (err != nil) != tt.wantErr {
+			t.Errorf("timeout %v: got error %v, want error: %v", tt.timeout, err, tt.wantErr)
+		}
+	}
+}
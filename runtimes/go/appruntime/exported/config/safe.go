@@ -0,0 +1,32 @@
+package config
+
+import "sync/atomic"
+
+// SafeRuntime holds a *Runtime that can be read and replaced concurrently.
+//
+// Readers never block and never observe a partially updated config.
+// The *Runtime returned by Load must be treated as immutable:
+// to change the config, build a new one (for example using [Runtime.Clone]
+// and [Runtime.Merge]) and Store it.
+//
+// The zero value holds no config.
+type SafeRuntime struct {
+	p atomic.Pointer[Runtime]
+}
+
+// NewSafeRuntime returns a SafeRuntime holding cfg.
+func NewSafeRuntime(cfg *Runtime) *SafeRuntime {
+	s := &SafeRuntime{}
+	s.Store(cfg)
+	return s
+}
+
+// Load returns the current config, or nil if none has been stored.
+func (s *SafeRuntime) Load() *Runtime {
+	return s.p.Load()
+}
+
+// Store replaces the current config with cfg.
+func (s *SafeRuntime) Store(cfg *Runtime) {
+	s.p.Store(cfg)
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSafeRuntime(t *testing.T) {
+	var empty SafeRuntime
+	if cfg := empty.Load(); cfg != nil {
+		t.Errorf("zero value: got %v, want nil", cfg)
+	}
+
+	initial := fullRuntime()
+	initial.EnvID = "env-" + initial.DeployID
+	s := NewSafeRuntime(initial)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				cfg := s.Load()
+				// Each stored config is internally consistent.
+				if cfg.EnvID != "env-"+cfg.DeployID {
+					t.Errorf("torn read: env id %q, deploy id %q", cfg.EnvID, cfg.DeployID)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		next := s.Load().Clone()
+		next.DeployID = fmt.Sprint(i)
+		next.EnvID = "env-" + next.DeployID
+		s.Store(next)
+	}
+	close(stop)
+	wg.Wait()
+
+	if got := s.Load().DeployID; got != "99" {
+		t.Errorf("got deploy id %q, want %q", got, "99")
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import "fmt"
+
+// CurrentSchemaVersion is the newest runtime config schema version
+// supported by this version of the runtime.
+const CurrentSchemaVersion = 1
+
+// migrations maps a schema version to the function that upgrades
+// a config from that version to the next one.
+var migrations = make(map[int]func(*Runtime))
+
+// RegisterMigration registers fn to upgrade a runtime config
+// from schema version from to version from+1.
+//
+// It must be called during initialization, and it panics if a
+// migration is already registered for the given version.
+func RegisterMigration(from int, fn func(*Runtime)) {
+	if _, dup := migrations[from]; dup {
+		panic(fmt.Sprintf("config: RegisterMigration called twice for version %d", from))
+	}
+	migrations[from] = fn
+}
+
+// migrateRuntime upgrades cfg in place to the given schema version,
+// running any registered migrations along the way.
+func migrateRuntime(cfg *Runtime, version int) error {
+	if cfg.SchemaVersion > version {
+		return fmt.Errorf("unsupported schema version %d (this runtime supports up to version %d)", cfg.SchemaVersion, version)
+	}
+	for v := cfg.SchemaVersion; v < version; v++ {
+		if fn := migrations[v]; fn != nil {
+			fn(cfg)
+		}
+	}
+	cfg.SchemaVersion = version
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMigrateRuntime(t *testing.T) {
+	const from = CurrentSchemaVersion
+	RegisterMigration(from, func(cfg *Runtime) {
+		// Pretend the next version renamed "dev" to "development".
+		if cfg.EnvType == "dev" {
+			cfg.EnvType = "development"
+		}
+	})
+	t.Cleanup(func() { delete(migrations, from) })
+
+	cfg := &Runtime{SchemaVersion: from, EnvType: "dev"}
+	if err := migrateRuntime(cfg, from+1); err != nil {
+		t.Fatalf("migrateRuntime: %v", err)
+	}
+	if cfg.SchemaVersion != from+1 {
+		t.Errorf("got schema version %d, want %d", cfg.SchemaVersion, from+1)
+	}
+	if cfg.EnvType != "development" {
+		t.Errorf("got env type %q, want %q", cfg.EnvType, "development")
+	}
+}
+
+func TestParseRuntimeSchemaVersion(t *testing.T) {
+	cfg, err := ParseRuntimeErr(encodeJSON(t, map[string]any{"app_id": "app", "api_base_url": "https://example.com"}), "")
+	if err != nil {
+		t.Fatalf("unversioned config: %v", err)
+	}
+	if cfg.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("got schema version %d, want %d", cfg.SchemaVersion, CurrentSchemaVersion)
+	}
+
+	_, err = ParseRuntimeErr(encodeJSON(t, map[string]any{"schema_version": CurrentSchemaVersion + 1}), "")
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Stage != StageUnmarshal {
+		t.Errorf("newer config: got %v, want a ParseError at stage %q", err, StageUnmarshal)
+	}
+}
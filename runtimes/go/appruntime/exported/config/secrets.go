@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SetSecret replaces the secret identified by ref with value.
+//
+// The supported refs are:
+//
+//   - "sql/<database>/password": the password of the SQL database
+//     with the given Encore name.
+//   - "sqlserver/<server>/client_key": the client key of the SQL server
+//     with the given server id.
+//   - "redis/<server>/auth": the password (AUTH string) of the Redis server
+//     with the given server id.
+//   - "redis/<server>/client_key": the client key of the Redis server
+//     with the given server id.
+//   - "authkey/<id>": the data of the auth key with the given key id.
+//   - "ecauthkey/<id>": the data of the Encore Cloud API auth key
+//     with the given key id.
+//   - "metrics/datadog/api_key": the Datadog API key.
+//
+// [Runtime.SecretRefs] lists the refs of the secrets set in a config.
+// It returns [ErrFrozen] if r is frozen.
+func (r *Runtime) SetSecret(ref, value string) error {
+	if r.frozen {
+		return ErrFrozen
+	}
+	for _, f := range r.secretFields() {
+		if f.ref == ref {
+			f.set(value)
+			return nil
+		}
+	}
+
+	kind, rest, _ := strings.Cut(ref, "/")
+	name, field, _ := strings.Cut(rest, "/")
+	switch {
+	case kind == "sql" && field == "password":
+		return fmt.Errorf("secret ref %q: unknown sql database %q", ref, name)
+	case kind == "redis" && (field == "auth" || field == "client_key"):
+		return fmt.Errorf("secret ref %q: unknown redis server %q", ref, name)
+	}
+	return fmt.Errorf("unknown secret ref %q", ref)
+}
+
+// SecretRefs returns the refs, as accepted by [Runtime.SetSecret],
+// of every secret that is set in the config, such as to rotate them.
+// The refs are in the order the secrets appear in the config.
+func (r *Runtime) SecretRefs() []string {
+	var refs []string
+	for _, f := range r.secretFields() {
+		if f.get() != "" {
+			refs = append(refs, f.ref)
+		}
+	}
+	return refs
+}
+
+// secretField is a secret field of the config, identified by its ref.
+type secretField struct {
+	ref string
+	ptr any // the *string or *[]byte holding the secret
+	get func() string
+	set func(string)
+}
+
+// secretFields returns every field of the config that may hold
+// a secret, set or not. It's the one list of secret fields:
+// [Runtime.Redacted], [DiffRuntime] and the secret resolver all use it.
+func (r *Runtime) secretFields() []secretField {
+	var fields []secretField
+	stringField := func(ref string, s *string) {
+		fields = append(fields, secretField{
+			ref: ref,
+			ptr: s,
+			get: func() string { return *s },
+			set: func(v string) { *s = v },
+		})
+	}
+	bytesField := func(ref string, b *[]byte) {
+		fields = append(fields, secretField{
+			ref: ref,
+			ptr: b,
+			get: func() string { return string(*b) },
+			set: func(v string) { *b = []byte(v) },
+		})
+	}
+
+	for i := range r.AuthKeys {
+		bytesField(fmt.Sprintf("authkey/%d", r.AuthKeys[i].KeyID), &r.AuthKeys[i].Data)
+	}
+	if r.EncoreCloudAPI != nil {
+		for i := range r.EncoreCloudAPI.AuthKeys {
+			bytesField(fmt.Sprintf("ecauthkey/%d", r.EncoreCloudAPI.AuthKeys[i].KeyID), &r.EncoreCloudAPI.AuthKeys[i].Data)
+		}
+	}
+	for id, srv := range r.SQLServers {
+		stringField(fmt.Sprintf("sqlserver/%d/client_key", id), &srv.ClientKey)
+	}
+	for _, db := range r.SQLDatabases {
+		stringField("sql/"+db.EncoreName+"/password", &db.Password)
+	}
+	for id, srv := range r.RedisServers {
+		stringField(fmt.Sprintf("redis/%d/auth", id), &srv.Password)
+		stringField(fmt.Sprintf("redis/%d/client_key", id), &srv.ClientKey)
+	}
+	if r.Metrics != nil && r.Metrics.Datadog != nil {
+		stringField("metrics/datadog/api_key", &r.Metrics.Datadog.APIKey)
+	}
+	return fields
+}
+
+// secretRefPrefix marks a secret field whose value is a reference
+// to be resolved using the resolver set with [WithSecretResolver].
+const secretRefPrefix = "secret://"
+
+// resolveSecrets replaces the secret fields of the config whose value
+// is of the form "secret://<ref>" by the result of calling resolve with ref.
+//
+// It reports every ref that could not be resolved.
+func (r *Runtime) resolveSecrets(resolve func(ref string) (string, error)) error {
+	var errs []error
+	for _, f := range r.secretFields() {
+		ref, ok := strings.CutPrefix(f.get(), secretRefPrefix)
+		if !ok {
+			continue
+		}
+		val, err := resolve(ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("secret %q: %w", ref, err))
+			continue
+		}
+		f.set(val)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetSecret(t *testing.T) {
+	cfg := fullRuntime()
+
+	if err := cfg.SetSecret("sql/users/password", "new-sql"); err != nil {
+		t.Errorf("sql: %v", err)
+	} else if got := cfg.SQLDatabases[0].Password; got != "new-sql" {
+		t.Errorf("sql: got password %q, want %q", got, "new-sql")
+	}
+
+	if err := cfg.SetSecret("redis/0/auth", "new-redis"); err != nil {
+		t.Errorf("redis: %v", err)
+	} else if got := cfg.RedisServers[0].Password; got != "new-redis" {
+		t.Errorf("redis: got password %q, want %q", got, "new-redis")
+	}
+
+	for _, ref := range []string{
+		"",
+		"sql/missing/password",
+		"sql/users/user",
+		"redis/1/auth",
+		"redis/cache/auth",
+		"tls/0/key",
+	} {
+		if err := cfg.SetSecret(ref, "value"); err == nil {
+			t.Errorf("%q: expected an error, got nil", ref)
+		}
+	}
+}
+
+func TestSecretRefs(t *testing.T) {
+	cfg := fullRuntime()
+	want := []string{
+		"authkey/1",
+		"ecauthkey/2",
+		"sqlserver/0/client_key",
+		"sql/users/password",
+		"redis/0/auth",
+		"metrics/datadog/api_key",
+	}
+	got := cfg.SecretRefs()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// Every ref can be set, and setting them all leaves no secret behind.
+	for _, ref := range got {
+		if err := cfg.SetSecret(ref, "rotated"); err != nil {
+			t.Errorf("SetSecret(%q): %v", ref, err)
+		}
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") || strings.Contains(string(data), testKeySecret) {
+		t.Errorf("config still contains secrets after rotation: %s", data)
+	}
+
+	if refs := (&Runtime{}).SecretRefs(); refs != nil {
+		t.Errorf("empty config: got %q, want nil", refs)
+	}
+}
+
+func TestWithSecretResolver(t *testing.T) {
+	secrets := map[string]string{
+		"db-password": "resolved-password",
+		"auth-key":    "resolved-auth-key-data",
+	}
+	resolve := func(ref string) (string, error) {
+		if v, ok := secrets[ref]; ok {
+			return v, nil
+		}
+		return "", fmt.Errorf("no such secret")
+	}
+
+	cfg := fullRuntime()
+	cfg.SQLDatabases[0].Password = "secret://db-password"
+	cfg.AuthKeys[0].Data = []byte("secret://auth-key")
+	config, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseRuntimeOptions(config, WithSecretResolver(resolve))
+	if err != nil {
+		t.Fatalf("ParseRuntimeOptions: %v", err)
+	}
+	if pw := got.SQLDatabases[0].Password; pw != "resolved-password" {
+		t.Errorf("indirected password: got %q, want %q", pw, "resolved-password")
+	}
+	if data := string(got.AuthKeys[0].Data); data != "resolved-auth-key-data" {
+		t.Errorf("indirected auth key: got %q, want %q", data, "resolved-auth-key-data")
+	}
+	if pw, want := got.RedisServers[0].Password, fullRuntime().RedisServers[0].Password; pw != want {
+		t.Errorf("plain password: got %q, want %q", pw, want)
+	}
+
+	// A failure names the ref.
+	cfg.RedisServers[0].Password = "secret://missing"
+	config, err = EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseRuntimeOptions(config, WithSecretResolver(resolve))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Stage != StageResolve {
+		t.Fatalf("got %v, want a resolve-stage *ParseError", err)
+	}
+	if want := `secret "missing": no such secret`; !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q does not contain %q", err, want)
+	}
+
+	// Without a resolver, references are left as-is.
+	got, err = ParseRuntimeErr(config, "")
+	if err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+	if pw := got.SQLDatabases[0].Password; pw != "secret://db-password" {
+		t.Errorf("no resolver: got password %q", pw)
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// The grace periods the runtime allows between the phases of a
+// graceful shutdown. They're fixed rather than configurable.
+const (
+	// ForceCloseTasksGrace is how long running tasks are given to
+	// finish after their contexts are canceled.
+	ForceCloseTasksGrace = 1 * time.Second
+	// ForceShutdownGrace is how long the force shutdown is given
+	// to tear down infrastructure resources before the process exits.
+	ForceShutdownGrace = 1 * time.Second
+)
+
+// ShutdownPhases are the effective timings of a graceful shutdown,
+// measured from when the shutdown is initiated.
+type ShutdownPhases struct {
+	// CancelHandlersAfter is when the contexts passed to API and
+	// PubSub Subscription handlers are canceled.
+	CancelHandlersAfter time.Duration
+	// ForceShutdownAfter is when the force shutdown begins, tearing
+	// down infrastructure resources and canceling the contexts passed
+	// to shutdown hooks.
+	ForceShutdownAfter time.Duration
+}
+
+// ShutdownPhases returns the timings the runtime uses when shutting
+// down gracefully, filling in the fallbacks for unset fields of
+// r.GracefulShutdown:
+//
+//   - Without a Total, the force shutdown begins after ShutdownTimeout,
+//     or 5 seconds if that's not set either. With a Total, it begins
+//     [ForceShutdownGrace] before the Total runs out, but no sooner
+//     than 500ms into the shutdown.
+//   - Without Handlers, handler contexts are canceled
+//     [ForceCloseTasksGrace] before the force shutdown begins.
+func (r *Runtime) ShutdownPhases() ShutdownPhases {
+	var gs GracefulShutdownTimings
+	if r.GracefulShutdown != nil {
+		gs = *r.GracefulShutdown
+	}
+
+	var p ShutdownPhases
+	if gs.Total == nil {
+		p.ForceShutdownAfter = r.ShutdownTimeout.Std()
+		if p.ForceShutdownAfter <= 0 {
+			p.ForceShutdownAfter = 5 * time.Second
+		}
+	} else {
+		p.ForceShutdownAfter = gs.Total.Std() - ForceShutdownGrace
+		if p.ForceShutdownAfter <= 0 {
+			p.ForceShutdownAfter = 500 * time.Millisecond
+		}
+	}
+
+	if gs.Handlers == nil {
+		p.CancelHandlersAfter = p.ForceShutdownAfter - ForceCloseTasksGrace
+	} else {
+		p.CancelHandlersAfter = gs.Handlers.Std()
+	}
+	p.CancelHandlersAfter = max(p.CancelHandlersAfter, 0)
+	return p
+}
+
+// validateGracefulShutdown checks that the shutdown timings are not
+// negative, and that the phases fit within the total shutdown time.
+func (r *Runtime) validateGracefulShutdown() []error {
+	var errs []error
+	if r.ShutdownTimeout < 0 {
+		errs = append(errs, fmt.Errorf("shutdown timeout %v: must not be negative", r.ShutdownTimeout))
+	}
+
+	gs := r.GracefulShutdown
+	if gs == nil {
+		return errs
+	}
+
+	// Total falls back to ShutdownTimeout during the migration period.
+	total, hasTotal := r.ShutdownTimeout, r.ShutdownTimeout > 0
+	if gs.Total != nil {
+		total, hasTotal = *gs.Total, true
+	}
+
+	for _, phase := range []struct {
+		name string
+		d    *Duration
+	}{
+		{"total", gs.Total},
+		{"shutdown hooks", gs.ShutdownHooks},
+		{"handlers", gs.Handlers},
+	} {
+		switch {
+		case phase.d == nil:
+		case *phase.d < 0:
+			errs = append(errs, fmt.Errorf("graceful shutdown %s %v: must not be negative", phase.name, *phase.d))
+		case phase.d != gs.Total && hasTotal && *phase.d > total:
+			errs = append(errs, fmt.Errorf("graceful shutdown %s %v: exceeds the total shutdown time of %v",
+				phase.name, *phase.d, total))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateGracefulShutdown(t *testing.T) {
+	dur := func(d time.Duration) *Duration {
+		v := Duration(d)
+		return &v
+	}
+	tests := []struct {
+		name    string
+		modify  func(cfg *Runtime)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Runtime) {},
+		},
+		{
+			name: "unset",
+			modify: func(cfg *Runtime) {
+				cfg.GracefulShutdown = nil
+			},
+		},
+		{
+			name: "handlers_exceed_total",
+			modify: func(cfg *Runtime) {
+				cfg.GracefulShutdown.Handlers = dur(15 * time.Second)
+			},
+			wantErr: "graceful shutdown handlers 15s: exceeds the total shutdown time of 10s",
+		},
+		{
+			name: "hooks_exceed_shutdown_timeout",
+			modify: func(cfg *Runtime) {
+				cfg.GracefulShutdown.Total = nil
+				cfg.GracefulShutdown.ShutdownHooks = dur(6 * time.Second)
+			},
+			wantErr: "graceful shutdown shutdown hooks 6s: exceeds the total shutdown time of 5s",
+		},
+		{
+			name: "no_total",
+			modify: func(cfg *Runtime) {
+				cfg.ShutdownTimeout = 0
+				cfg.GracefulShutdown.Total = nil
+			},
+		},
+		{
+			name: "negative_phase",
+			modify: func(cfg *Runtime) {
+				cfg.GracefulShutdown.ShutdownHooks = dur(-time.Second)
+			},
+			wantErr: "graceful shutdown shutdown hooks -1s: must not be negative",
+		},
+		{
+			name: "negative_total",
+			modify: func(cfg *Runtime) {
+				cfg.GracefulShutdown.Total = dur(-time.Second)
+			},
+			wantErr: "graceful shutdown total -1s: must not be negative",
+		},
+		{
+			name: "negative_shutdown_timeout",
+			modify: func(cfg *Runtime) {
+				cfg.ShutdownTimeout = Duration(-time.Second)
+			},
+			wantErr: "shutdown timeout -1s: must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fullRuntime()
+			tt.modify(cfg)
+			err := errors.Join(cfg.validateGracefulShutdown()...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The defaults are always valid.
+	cfg := &Runtime{APIBaseURL: "https://example.com"}
+	cfg.ApplyDefaults()
+	if err := errors.Join(cfg.validateGracefulShutdown()...); err != nil {
+		t.Errorf("defaults: unexpected error: %v", err)
+	}
+}
+
+func TestShutdownPhases(t *testing.T) {
+	dur := func(d time.Duration) *Duration { v := Duration(d); return &v }
+	tests := []struct {
+		name string
+		cfg  Runtime
+		want ShutdownPhases
+	}{
+		{
+			name: "unset",
+			want: ShutdownPhases{CancelHandlersAfter: 4 * time.Second, ForceShutdownAfter: 5 * time.Second},
+		},
+		{
+			name: "shutdown_timeout",
+			cfg:  Runtime{ShutdownTimeout: Duration(10 * time.Second)},
+			want: ShutdownPhases{CancelHandlersAfter: 9 * time.Second, ForceShutdownAfter: 10 * time.Second},
+		},
+		{
+			name: "total",
+			cfg:  Runtime{GracefulShutdown: &GracefulShutdownTimings{Total: dur(10 * time.Second)}},
+			want: ShutdownPhases{CancelHandlersAfter: 8 * time.Second, ForceShutdownAfter: 9 * time.Second},
+		},
+		{
+			name: "handlers",
+			cfg:  Runtime{GracefulShutdown: &GracefulShutdownTimings{Total: dur(10 * time.Second), Handlers: dur(2 * time.Second)}},
+			want: ShutdownPhases{CancelHandlersAfter: 2 * time.Second, ForceShutdownAfter: 9 * time.Second},
+		},
+		{
+			name: "short_total",
+			cfg:  Runtime{GracefulShutdown: &GracefulShutdownTimings{Total: dur(time.Second)}},
+			want: ShutdownPhases{CancelHandlersAfter: 0, ForceShutdownAfter: 500 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.ShutdownPhases(); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
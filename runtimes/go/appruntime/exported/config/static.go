@@ -0,0 +1,60 @@
+package config
+
+import (
+	"slices"
+)
+
+// StaticService describes a service bundled in the binary,
+// as known from the static config.
+type StaticService struct {
+	// Name is the name of the service.
+	Name string
+
+	// Root is the filesystem root of the service.
+	// It is only known when running tests.
+	Root string
+
+	// Subscriptions are the pubsub subscriptions in the service,
+	// as the names of the subscriptions keyed by the topic name.
+	Subscriptions map[string][]string
+}
+
+// Services returns the names of the services bundled in the binary,
+// in sorted order.
+func (s *Static) Services() []string {
+	names := slices.Clone(s.BundledServices)
+	for name := range s.TestServiceMap {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Service returns the bundled service with the given name.
+func (s *Static) Service(name string) (*StaticService, bool) {
+	root, inTest := s.TestServiceMap[name]
+	if !inTest && !slices.Contains(s.BundledServices, name) {
+		return nil, false
+	}
+
+	svc := &StaticService{Name: name, Root: root}
+	for topicName, topic := range s.PubsubTopics {
+		if topic == nil {
+			continue
+		}
+		for subName, sub := range topic.Subscriptions {
+			if sub != nil && sub.Service == name {
+				if svc.Subscriptions == nil {
+					svc.Subscriptions = make(map[string][]string)
+				}
+				svc.Subscriptions[topicName] = append(svc.Subscriptions[topicName], subName)
+			}
+		}
+	}
+	for _, subs := range svc.Subscriptions {
+		slices.Sort(subs)
+	}
+	return svc, true
+}
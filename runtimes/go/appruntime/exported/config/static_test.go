@@ -0,0 +1,57 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStaticServices(t *testing.T) {
+	cfg := &Static{
+		BundledServices: []string{"users", "billing"},
+		PubsubTopics: map[string]*StaticPubsubTopic{
+			"signups": {Subscriptions: map[string]*StaticPubsubSubscription{
+				"send-welcome": {Service: "users"},
+				"create-trial": {Service: "billing"},
+				"audit":        {Service: "users"},
+			}},
+			"invoices": {Subscriptions: map[string]*StaticPubsubSubscription{
+				"email-invoice": {Service: "users"},
+			}},
+		},
+	}
+
+	if got, want := cfg.Services(), []string{"billing", "users"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Services() = %q, want %q", got, want)
+	}
+	if cfg.BundledServices[0] != "users" {
+		t.Error("Services modified BundledServices")
+	}
+
+	svc, ok := cfg.Service("users")
+	if !ok {
+		t.Fatal("Service(users): not found")
+	}
+	want := &StaticService{
+		Name: "users",
+		Subscriptions: map[string][]string{
+			"signups":  {"audit", "send-welcome"},
+			"invoices": {"email-invoice"},
+		},
+	}
+	if !reflect.DeepEqual(svc, want) {
+		t.Errorf("Service(users) = %+v, want %+v", svc, want)
+	}
+
+	if _, ok := cfg.Service("orders"); ok {
+		t.Error("Service(orders): got ok, want not found")
+	}
+
+	// When testing, the services come from the test service map.
+	cfg = &Static{Testing: true, TestServiceMap: map[string]string{"users": "/app/users"}}
+	if got, want := cfg.Services(), []string{"users"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("testing: Services() = %q, want %q", got, want)
+	}
+	if svc, ok := cfg.Service("users"); !ok || svc.Root != "/app/users" {
+		t.Errorf("testing: Service(users) = %+v, %v", svc, ok)
+	}
+}
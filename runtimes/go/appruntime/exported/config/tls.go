@@ -0,0 +1,117 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// validateSQLTLS checks that the TLS material of each SQL server
+// is consistent and parses.
+func (r *Runtime) validateSQLTLS() []error {
+	var errs []error
+	for id, srv := range r.SQLServers {
+		for _, err := range validateTLSMaterial(srv.ServerCACert, srv.ClientCert, srv.ClientKey) {
+			errs = append(errs, fmt.Errorf("sql server %d (host %q): %w", id, srv.Host, err))
+		}
+	}
+	return errs
+}
+
+// validateRedisServers checks that the host of each Redis server
+// is well-formed, and that its TLS material is consistent and parses.
+func (r *Runtime) validateRedisServers() []error {
+	var errs []error
+	for id, srv := range r.RedisServers {
+		if err := validateRedisHost(srv.Host); err != nil {
+			errs = append(errs, fmt.Errorf("redis server %d: %w", id, err))
+		}
+		for _, err := range validateTLSMaterial(srv.ServerCACert, srv.ClientCert, srv.ClientKey) {
+			errs = append(errs, fmt.Errorf("redis server %d (host %q): %w", id, srv.Host, err))
+		}
+	}
+	return errs
+}
+
+// validateRedisHost checks that host is a unix socket path, a hostname,
+// or a hostname and port.
+func validateRedisHost(host string) error {
+	switch {
+	case host == "":
+		return errors.New("missing host")
+	case strings.HasPrefix(host, "/"):
+		return nil // unix socket
+	case !strings.Contains(host, ":"):
+		return nil // hostname
+	}
+	h, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return fmt.Errorf("invalid host %q: %w", host, err)
+	}
+	if h == "" {
+		return fmt.Errorf("invalid host %q: missing hostname", host)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid host %q: invalid port %q", host, port)
+	}
+	return nil
+}
+
+// usesTLS reports whether connections to the Redis server use TLS,
+// which is implied by setting any of the TLS material.
+func (srv *RedisServer) usesTLS() bool {
+	return srv.EnableTLS || srv.ServerCACert != "" || srv.ClientCert != "" || srv.ClientKey != ""
+}
+
+// validateTLSMaterial checks the PEM-encoded TLS material of a server.
+// Each value may be empty, but a client cert and key must be given together.
+func validateTLSMaterial(caCert, clientCert, clientKey string) []error {
+	var errs []error
+	if caCert != "" {
+		if err := parseCertsPEM(caCert); err != nil {
+			errs = append(errs, fmt.Errorf("invalid server ca cert: %w", err))
+		}
+	}
+
+	switch {
+	case clientCert != "" && clientKey == "":
+		errs = append(errs, errors.New("client cert is set but client key is missing"))
+	case clientCert == "" && clientKey != "":
+		errs = append(errs, errors.New("client key is set but client cert is missing"))
+	case clientCert != "":
+		if _, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey)); err != nil {
+			errs = append(errs, fmt.Errorf("invalid client cert and key: %w", err))
+		}
+	}
+	return errs
+}
+
+// parseCertsPEM checks that data consists of one or more
+// PEM-encoded certificates.
+func parseCertsPEM(data string) error {
+	rest := []byte(data)
+	n := 0
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("unexpected PEM block of type %q", block.Type)
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return err
+		}
+		n++
+	}
+	if n == 0 {
+		return errors.New("no PEM-encoded certificates found")
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertPEM and testKeyPEM are a self-signed certificate
+// and its private key, used in fullRuntime.
+var testCertPEM, testKeyPEM = generateTestCert()
+
+// testKeySecret is a part of testKeyPEM that must not
+// appear in redacted output.
+var testKeySecret = strings.Split(testKeyPEM, "\n")[1]
+
+func generateTestCert() (certPEM, keyPEM string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+	certPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certPEM, keyPEM
+}
+
+func TestValidateSQLTLS(t *testing.T) {
+	otherCert, otherKey := generateTestCert()
+	tests := []struct {
+		name    string
+		modify  func(srv *SQLServer)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(srv *SQLServer) {},
+		},
+		{
+			name: "no_tls",
+			modify: func(srv *SQLServer) {
+				srv.ServerCACert, srv.ClientCert, srv.ClientKey = "", "", ""
+			},
+		},
+		{
+			name: "cert_without_key",
+			modify: func(srv *SQLServer) {
+				srv.ClientKey = ""
+			},
+			wantErr: `sql server 0 (host "db.example.com:5432"): client cert is set but client key is missing`,
+		},
+		{
+			name: "key_without_cert",
+			modify: func(srv *SQLServer) {
+				srv.ClientCert = ""
+			},
+			wantErr: `sql server 0 (host "db.example.com:5432"): client key is set but client cert is missing`,
+		},
+		{
+			name: "mismatched_pair",
+			modify: func(srv *SQLServer) {
+				srv.ClientCert = otherCert
+			},
+			wantErr: `sql server 0 (host "db.example.com:5432"): invalid client cert and key`,
+		},
+		{
+			name: "malformed_ca_pem",
+			modify: func(srv *SQLServer) {
+				srv.ServerCACert = "-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydA==\n-----END CERTIFICATE-----\n"
+			},
+			wantErr: `sql server 0 (host "db.example.com:5432"): invalid server ca cert`,
+		},
+		{
+			name: "not_pem",
+			modify: func(srv *SQLServer) {
+				srv.ServerCACert = "ca-cert"
+			},
+			wantErr: "invalid server ca cert: no PEM-encoded certificates found",
+		},
+		{
+			name: "key_as_ca",
+			modify: func(srv *SQLServer) {
+				srv.ServerCACert = otherKey
+			},
+			wantErr: `invalid server ca cert: unexpected PEM block of type "EC PRIVATE KEY"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fullRuntime()
+			tt.modify(cfg.SQLServers[0])
+			err := errors.Join(cfg.validateSQLTLS()...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRedisServers(t *testing.T) {
+	_, otherKey := generateTestCert()
+	tests := []struct {
+		name    string
+		modify  func(srv *RedisServer)
+		wantErr string
+	}{
+		{
+			name:   "plaintext",
+			modify: func(srv *RedisServer) { srv.EnableTLS = false },
+		},
+		{
+			name: "tls",
+			modify: func(srv *RedisServer) {
+				srv.ServerCACert, srv.ClientCert, srv.ClientKey = testCertPEM, testCertPEM, testKeyPEM
+			},
+		},
+		{name: "hostname_only", modify: func(srv *RedisServer) { srv.Host = "redis" }},
+		{name: "unix_socket", modify: func(srv *RedisServer) { srv.Host = "/tmp/redis.sock" }},
+		{
+			name:    "missing_host",
+			modify:  func(srv *RedisServer) { srv.Host = "" },
+			wantErr: "redis server 0: missing host",
+		},
+		{
+			name:    "bad_port",
+			modify:  func(srv *RedisServer) { srv.Host = "redis:99999" },
+			wantErr: `redis server 0: invalid host "redis:99999": invalid port "99999"`,
+		},
+		{
+			name:    "missing_hostname",
+			modify:  func(srv *RedisServer) { srv.Host = ":6379" },
+			wantErr: `redis server 0: invalid host ":6379": missing hostname`,
+		},
+		{
+			name:    "unparseable_address",
+			modify:  func(srv *RedisServer) { srv.Host = "redis:6379:1" },
+			wantErr: `redis server 0: invalid host "redis:6379:1"`,
+		},
+		{
+			name:    "cert_without_key",
+			modify:  func(srv *RedisServer) { srv.ClientCert = testCertPEM },
+			wantErr: `redis server 0 (host "redis.example.com:6379"): client cert is set but client key is missing`,
+		},
+		{
+			name:    "invalid_ca",
+			modify:  func(srv *RedisServer) { srv.ServerCACert = otherKey },
+			wantErr: `redis server 0 (host "redis.example.com:6379"): invalid server ca cert`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fullRuntime()
+			tt.modify(cfg.RedisServers[0])
+			errs := cfg.validateRedisServers()
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.wantErr) {
+				t.Errorf("got %v, want an error containing %q", errs, tt.wantErr)
+			}
+		})
+	}
+}
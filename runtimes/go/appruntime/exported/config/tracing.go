@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// OTelTracingEnabled reports whether r.Tracing asks for traces to be
+// exported using OpenTelemetry: an endpoint is configured and the
+// sample rate isn't zero. It is unrelated to Encore's own request
+// tracing, and the runtime does not act on it.
+func (r *Runtime) OTelTracingEnabled() bool {
+	t := r.Tracing
+	if t == nil || t.Endpoint == "" {
+		return false
+	}
+	return t.SampleRate == nil || *t.SampleRate > 0
+}
+
+// validateTracing checks that the tracing endpoint is an absolute
+// http or https URL, and that the sample rate is between 0 and 1.
+func (r *Runtime) validateTracing() []error {
+	t := r.Tracing
+	if t == nil {
+		return nil
+	}
+
+	var errs []error
+	if t.Provider == "" {
+		errs = append(errs, errors.New("tracing: missing provider"))
+	}
+	if t.Endpoint == "" {
+		errs = append(errs, errors.New("tracing: missing endpoint"))
+	} else if u, err := url.Parse(t.Endpoint); err != nil {
+		errs = append(errs, fmt.Errorf("tracing endpoint %q: %w", t.Endpoint, err))
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("tracing endpoint %q: must be an http or https url with a host", t.Endpoint))
+	}
+	if sr := t.SampleRate; sr != nil && !(*sr >= 0 && *sr <= 1) {
+		errs = append(errs, fmt.Errorf("tracing sample rate %v: must be between 0 and 1", *sr))
+	}
+	return errs
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTracing(t *testing.T) {
+	rate := func(f float64) *float64 { return &f }
+	tests := []struct {
+		name        string
+		tracing     *Tracing
+		wantErr     string
+		wantEnabled bool
+	}{
+		{name: "unset"},
+		{
+			name:        "valid",
+			tracing:     &Tracing{Provider: "otlp", Endpoint: "https://otel-collector:4318", SampleRate: rate(0.25)},
+			wantEnabled: true,
+		},
+		{
+			name:        "default_sample_rate",
+			tracing:     &Tracing{Provider: "otlp", Endpoint: "http://localhost:4318"},
+			wantEnabled: true,
+		},
+		{
+			name:    "zero_sample_rate",
+			tracing: &Tracing{Provider: "otlp", Endpoint: "http://localhost:4318", SampleRate: rate(0)},
+		},
+		{
+			name:        "sample_rate_out_of_range",
+			tracing:     &Tracing{Provider: "otlp", Endpoint: "http://localhost:4318", SampleRate: rate(1.5)},
+			wantErr:     "tracing sample rate 1.5: must be between 0 and 1",
+			wantEnabled: true,
+		},
+		{
+			name:    "bad_endpoint",
+			tracing: &Tracing{Provider: "otlp", Endpoint: "otel-collector:4318"},
+			wantErr: `tracing endpoint "otel-collector:4318": must be an http or https url with a host`,
+			// The endpoint is set, so it's reported as enabled; only
+			// validation catches that it's malformed.
+			wantEnabled: true,
+		},
+		{
+			name:    "missing_endpoint",
+			tracing: &Tracing{Provider: "otlp"},
+			wantErr: "tracing: missing endpoint",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Runtime{Tracing: tt.tracing}
+			errs := cfg.validateTracing()
+			if tt.wantErr == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+			} else if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.wantErr) {
+				t.Fatalf("got %v, want an error containing %q", errs, tt.wantErr)
+			}
+			if got := cfg.OTelTracingEnabled(); got != tt.wantEnabled {
+				t.Errorf("OTelTracingEnabled() = %v, want %v", got, tt.wantEnabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Validate checks the runtime config for internal consistency.
+// It reports every problem found, joined together using [errors.Join].
+func (r *Runtime) Validate() error {
+	return errors.Join(r.validate(validateOptions{})...)
+}
+
+// ValidateRuntimeString parses the encoded runtime config leniently and
+// checks it using [Runtime.Validate], returning every problem found.
+// Problems that [ParseRuntimeErr] would stop at, such as a missing API
+// base URL, are reported along with the rest. If the config can't be
+// decoded or unmarshaled, that error is the only problem.
+//
+// It returns an empty slice if the config is valid. Unlike [ParseRuntime]
+// it never terminates the process, making it suitable for tools that check
+// a config before it's deployed.
+func ValidateRuntimeString(config string) []error {
+	// Validate repeats the checks made on every parse, so skip
+	// those to report them along with everything else.
+	cfg, err := parseRuntime(context.Background(), config, func(o *parseOptions) { o.skipParseChecks = true })
+	if err != nil {
+		return []error{err}
+	}
+	errs := cfg.validate(validateOptions{})
+	if errs == nil {
+		errs = []error{}
+	}
+	return errs
+}
+
+// validateOptions configures the checks made by (*Runtime).validate.
+// The zero value uses the defaults.
+type validateOptions struct {
+	// redisDatabases is the number of databases each Redis server has,
+	// bounding the valid database indices. If zero, it defaults to 16.
+	redisDatabases int
+}
+
+// validate returns all the problems found with the runtime config.
+func (r *Runtime) validate(opts validateOptions) []error {
+	var errs []error
+	if err := validateAPIBaseURL(r.APIBaseURL); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, r.validateEnv()...)
+	if err := r.validateEnvName(); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, r.validateAuthKeys()...)
+	errs = append(errs, r.validateSQLDatabases()...)
+	errs = append(errs, r.validateSQLTLS()...)
+	errs = append(errs, r.validateRedisServers()...)
+	errs = append(errs, r.validateRedisDatabases(opts.redisDatabases)...)
+	errs = append(errs, r.validatePubsubTopics()...)
+	errs = append(errs, r.validatePubsubSubscriptions()...)
+	errs = append(errs, r.validateGateways()...)
+	errs = append(errs, r.validateServiceDiscovery()...)
+	if _, err := r.TrustedProxyNets(); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, r.validateMetrics()...)
+	errs = append(errs, r.validateGracefulShutdown()...)
+	if err := r.validateRequests(); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, r.validateTracing()...)
+	return errs
+}
+
+// validateAPIBaseURL checks that apiBaseURL is an absolute http or https URL.
+func validateAPIBaseURL(apiBaseURL string) error {
+	u, err := url.Parse(apiBaseURL)
+	switch {
+	case apiBaseURL == "":
+		return errors.New("missing api base url")
+	case err != nil:
+		return fmt.Errorf("api base url %q: %w", apiBaseURL, err)
+	case u.Scheme == "":
+		return fmt.Errorf("api base url %q: missing scheme", apiBaseURL)
+	case u.Scheme != "http" && u.Scheme != "https":
+		return fmt.Errorf("api base url %q: unsupported scheme %q (must be http or https)", apiBaseURL, u.Scheme)
+	case u.Host == "":
+		return fmt.Errorf("api base url %q: missing host", apiBaseURL)
+	}
+	return nil
+}
+
+func (r *Runtime) validateSQLDatabases() []error {
+	// Databases without any servers usually means the config was
+	// truncated, so report that rather than every dangling reference.
+	if len(r.SQLDatabases) > 0 && len(r.SQLServers) == 0 {
+		return []error{errors.New("sql databases are declared but no sql servers are configured")}
+	}
+
+	var errs []error
+
+	// Server IDs are indices into SQLServers, so they can't collide
+	// themselves; instead flag servers that are listed more than once.
+	seen := make(map[string]int, len(r.SQLServers))
+	for id, srv := range r.SQLServers {
+		if prev, ok := seen[srv.Host]; ok {
+			errs = append(errs, fmt.Errorf("sql server %d: duplicate of server %d (host %q)", id, prev, srv.Host))
+			continue
+		}
+		seen[srv.Host] = id
+	}
+
+	for _, db := range r.SQLDatabases {
+		if db.ServerID < 0 || db.ServerID >= len(r.SQLServers) {
+			errs = append(errs, fmt.Errorf("sql database %q: unknown server id %d", db.EncoreName, db.ServerID))
+		}
+		errs = append(errs, db.validatePool()...)
+	}
+	return errs
+}
+
+// checkExpectedDatabases reports the SQL databases in the config
+// that are not in expected, and those in expected that are missing.
+func (r *Runtime) checkExpectedDatabases(expected []string) []error {
+	var errs []error
+	for _, db := range r.SQLDatabases {
+		if !slices.Contains(expected, db.EncoreName) {
+			errs = append(errs, fmt.Errorf("sql database %q: not expected", db.EncoreName))
+		}
+	}
+	for _, name := range expected {
+		if _, ok := r.SQLDatabase(name); !ok {
+			errs = append(errs, fmt.Errorf("sql database %q: expected but missing", name))
+		}
+	}
+	return errs
+}
+
+// defaultRedisDatabases is the number of databases a Redis server
+// has unless configured otherwise.
+const defaultRedisDatabases = 16
+
+func (r *Runtime) validateRedisDatabases(numDatabases int) []error {
+	if numDatabases <= 0 {
+		numDatabases = defaultRedisDatabases
+	}
+
+	// Databases sharing an index on the same server are only
+	// kept apart by their key prefix, as in local development.
+	type location struct {
+		serverID, database int
+		keyPrefix          string
+	}
+	seen := make(map[location]string, len(r.RedisDatabases))
+
+	var errs []error
+	for _, db := range r.RedisDatabases {
+		if db.ServerID < 0 || db.ServerID >= len(r.RedisServers) {
+			errs = append(errs, fmt.Errorf("redis database %q: unknown server id %d", db.EncoreName, db.ServerID))
+		}
+		if db.Database < 0 || db.Database >= numDatabases {
+			errs = append(errs, fmt.Errorf("redis database %q: database index %d out of range [0, %d]",
+				db.EncoreName, db.Database, numDatabases-1))
+		}
+
+		loc := location{db.ServerID, db.Database, db.KeyPrefix}
+		if other, ok := seen[loc]; ok {
+			errs = append(errs, fmt.Errorf("redis database %q: uses the same server and database index %d as %q",
+				db.EncoreName, db.Database, other))
+		} else {
+			seen[loc] = db.EncoreName
+		}
+	}
+	return errs
+}
+
+func (r *Runtime) validatePubsubTopics() []error {
+	var errs []error
+
+	// Topics are keyed by their Encore name, so two entries claiming
+	// the same EncoreName means one of them would shadow the other.
+	keys := make([]string, 0, len(r.PubsubTopics))
+	for key := range r.PubsubTopics {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var names []string
+	keysByName := make(map[string][]string)
+	for _, key := range keys {
+		topic := r.PubsubTopics[key]
+		if topic == nil {
+			continue
+		}
+		name := topic.EncoreName
+		if name == "" {
+			name = key
+		}
+		if _, ok := keysByName[name]; !ok {
+			names = append(names, name)
+		}
+		keysByName[name] = append(keysByName[name], key)
+
+		if topic.ProviderID < 0 || topic.ProviderID >= len(r.PubsubProviders) {
+			errs = append(errs, fmt.Errorf("pubsub topic %q: unknown provider id %d", name, topic.ProviderID))
+		}
+	}
+	for _, name := range names {
+		if keys := keysByName[name]; len(keys) > 1 {
+			errs = append(errs, fmt.Errorf("pubsub topic %q: defined more than once (keys %q)", name, keys))
+		}
+	}
+	return errs
+}
+
+// duplicateKey is a key that appears more than once in a JSON object.
+type duplicateKey struct {
+	parent string // the path of the object, as described on duplicateKeyErrors
+	key    string
+}
+
+// duplicateKeyErrors reports the keys that appear more than once in
+// the JSON-encoded runtime config in data. encoding/json silently keeps
+// the last of several duplicate keys, so this has to be checked before
+// the config is unmarshalled.
+//
+// Duplicate entries of the "pubsub_topics" and "service_discovery" maps
+// are always reported, since they define the same topic or service twice.
+// If all is set, duplicate keys in any other object are reported too,
+// naming the key by its path: object keys joined with "." and array
+// indices in brackets, like "sql_servers[0].host".
+//
+// It scans the whole config, so it's only used when parsing with
+// [WithStrict] or [WithValidate], and all is only set in strict mode.
+// It returns nil if data is not valid JSON, leaving that to be
+// reported when the config is unmarshalled.
+func duplicateKeyErrors(data []byte, all bool) []error {
+	var dups []duplicateKey
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := scanDuplicateKeys(dec, "", &dups); err != nil {
+		return nil
+	}
+
+	var errs []error
+	for _, d := range dups {
+		switch {
+		case d.parent == "pubsub_topics":
+			errs = append(errs, fmt.Errorf("pubsub topic %q: defined more than once", d.key))
+		case d.parent == "service_discovery":
+			errs = append(errs, fmt.Errorf("service discovery %q: defined more than once", d.key))
+		case all:
+			errs = append(errs, fmt.Errorf("duplicate key %q", joinPath(d.parent, d.key)))
+		}
+	}
+	return errs
+}
+
+// scanDuplicateKeys reads the next JSON value from dec, at the given
+// path, appending any duplicate keys in it to dups.
+func scanDuplicateKeys(dec *json.Decoder, path string, dups *[]duplicateKey) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := tok.(string)
+			if seen[key] {
+				*dups = append(*dups, duplicateKey{parent: path, key: key})
+			}
+			seen[key] = true
+			if err := scanDuplicateKeys(dec, joinPath(path, key), dups); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := scanDuplicateKeys(dec, path+"["+strconv.Itoa(i)+"]", dups); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	_, err = dec.Token() // the closing delimiter
+	return err
+}
+
+func (r *Runtime) validateGateways() []error {
+	// We can only tell which services exist if the config lists them.
+	known := r.knownServices()
+	hosted := make(map[string]bool, len(r.HostedServices))
+	for _, svc := range r.HostedServices {
+		hosted[svc] = true
+	}
+
+	var errs []error
+	seen := make(map[string]bool, len(r.Gateways))
+	for i, gw := range r.Gateways {
+		if gw.Name == "" {
+			errs = append(errs, fmt.Errorf("gateway %d: missing name", i))
+		} else if seen[gw.Name] {
+			errs = append(errs, fmt.Errorf("gateway %q: defined more than once", gw.Name))
+		}
+		seen[gw.Name] = true
+
+		for _, other := range r.Gateways[:i] {
+			if hostsOverlap(gw.Host, other.Host) {
+				errs = append(errs, fmt.Errorf("gateway %q: host %q overlaps with host %q of gateway %q",
+					gw.Name, gw.Host, other.Host, other.Name))
+			}
+		}
+
+		errs = append(errs, gw.validateRateLimit()...)
+
+		for _, svc := range gw.Services {
+			switch {
+			case len(hosted) > 0 && !hosted[svc]:
+				// A gateway can only route to services hosted alongside it.
+				errs = append(errs, fmt.Errorf("gateway %q: service %q is not hosted by this instance", gw.Name, svc))
+			case len(known) > 0 && !known[svc]:
+				errs = append(errs, fmt.Errorf("gateway %q: unknown service %q", gw.Name, svc))
+			}
+		}
+	}
+	return errs
+}
+
+// knownServices returns the services the config knows about,
+// either because they're hosted here or can be discovered.
+func (r *Runtime) knownServices() map[string]bool {
+	known := make(map[string]bool, len(r.HostedServices)+len(r.ServiceDiscovery))
+	for _, svc := range r.HostedServices {
+		known[svc] = true
+	}
+	for svc := range r.ServiceDiscovery {
+		known[svc] = true
+	}
+	return known
+}
+
+// hostsOverlap reports whether the gateway host patterns a and b
+// could both match the same host. A pattern starting with "*."
+// matches any subdomain of the rest of the pattern.
+//
+// Empty hosts never overlap, since they don't route by host.
+func hostsOverlap(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if a == b {
+		return true
+	}
+	matches := func(pattern, host string) bool {
+		suffix, ok := strings.CutPrefix(pattern, "*")
+		return ok && strings.HasSuffix(host, suffix)
+	}
+	return matches(a, b) || matches(b, a)
+}
@@ -0,0 +1,530 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := fullRuntime().Validate(); err != nil {
+		t.Fatalf("valid config: unexpected error: %v", err)
+	}
+
+	cfg := fullRuntime()
+	cfg.APIBaseURL = "api.example.com"
+	cfg.SQLDatabases[0].ServerID = 3
+	cfg.RedisDatabases[0].ServerID = -1
+	cfg.Gateways = append(cfg.Gateways, cfg.Gateways[0])
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("invalid config: expected an error, got nil")
+	}
+	for _, want := range []string{
+		`api base url "api.example.com"`,
+		`sql database "users": unknown server id 3`,
+		`redis database "cache": unknown server id -1`,
+		`gateway "api-gateway": defined more than once`,
+		`gateway "api-gateway": host "api.example.com" overlaps with host "api.example.com"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if n := len(cfg.validate(validateOptions{})); n != 5 {
+		t.Errorf("got %d problems, want 5", n)
+	}
+}
+
+func TestValidateRuntimeString(t *testing.T) {
+	valid, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := ValidateRuntimeString(valid); errs == nil || len(errs) != 0 {
+		t.Errorf("valid config: got %v, want an empty slice", errs)
+	}
+
+	cfg := fullRuntime()
+	cfg.EnvType = "prod"
+	cfg.SQLDatabases[0].ServerID = 3
+	cfg.RedisDatabases[0].ServerID = -1
+	invalid, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := ValidateRuntimeString(invalid)
+	want := []string{
+		`env type "prod": unknown value`,
+		`sql database "users": unknown server id 3`,
+		`redis database "cache": unknown server id -1`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d problems %v, want %d", len(errs), errs, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(errs[i].Error(), w) {
+			t.Errorf("problem %d: got %q, want it to contain %q", i, errs[i], w)
+		}
+	}
+
+	// Problems the parser stops at are reported with the rest.
+	cfg = fullRuntime()
+	cfg.APIBaseURL = ""
+	cfg.EnvName = "Not Valid!"
+	cfg.SQLDatabases[0].ServerID = 3
+	cfg.PubsubTopics["signups"].ProviderID = 5
+	invalid, err = EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs = ValidateRuntimeString(invalid)
+	for _, w := range []string{
+		"missing api base url",
+		`env name "Not Valid!"`,
+		`sql database "users": unknown server id 3`,
+		`pubsub topic "signups": unknown provider id 5`,
+	} {
+		if !strings.Contains(errors.Join(errs...).Error(), w) {
+			t.Errorf("got problems %v, want one containing %q", errs, w)
+		}
+	}
+
+	// A config that can't be parsed reports just that.
+	if errs := ValidateRuntimeString("not base64!"); len(errs) != 1 {
+		t.Errorf("unparseable config: got %v, want a single problem", errs)
+	}
+}
+
+func TestParseRuntimeValidated(t *testing.T) {
+	cfg := fullRuntime()
+	cfg.SQLDatabases[0].ServerID = 1
+	config, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParseRuntimeErr(config, ""); err != nil {
+		t.Errorf("ParseRuntimeErr: unexpected error: %v", err)
+	}
+	if _, err := ParseRuntimeValidated(config, ""); err == nil {
+		t.Error("ParseRuntimeValidated: expected an error, got nil")
+	} else if errors.Unwrap(err) == nil {
+		t.Errorf("ParseRuntimeValidated: error %q does not wrap the validation error", err)
+	}
+}
+
+func TestValidateSQLDatabases(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Runtime)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Runtime) {},
+		},
+		{
+			name: "dangling_server",
+			modify: func(cfg *Runtime) {
+				cfg.SQLDatabases[0].ServerID = 1
+			},
+			wantErr: `sql database "users": unknown server id 1`,
+		},
+		{
+			name: "duplicate_server",
+			modify: func(cfg *Runtime) {
+				srv := *cfg.SQLServers[0]
+				cfg.SQLServers = append(cfg.SQLServers, &srv)
+			},
+			wantErr: `sql server 1: duplicate of server 0`,
+		},
+		{
+			name: "no_servers",
+			modify: func(cfg *Runtime) {
+				cfg.SQLServers = nil
+			},
+			wantErr: `sql databases are declared but no sql servers are configured`,
+		},
+		{
+			name: "no_databases_or_servers",
+			modify: func(cfg *Runtime) {
+				cfg.SQLServers = nil
+				cfg.SQLDatabases = nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fullRuntime()
+			tt.modify(cfg)
+			err := errors.Join(cfg.validateSQLDatabases()...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRedisDatabases(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(cfg *Runtime)
+		databases int
+		wantErrs  []string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Runtime) {},
+		},
+		{
+			name: "dangling_server",
+			modify: func(cfg *Runtime) {
+				cfg.RedisDatabases[0].ServerID = 2
+			},
+			wantErrs: []string{`redis database "cache": unknown server id 2`},
+		},
+		{
+			name: "index_out_of_range",
+			modify: func(cfg *Runtime) {
+				cfg.RedisDatabases[0].Database = -1
+				cfg.RedisDatabases = append(cfg.RedisDatabases, &RedisDatabase{EncoreName: "sessions", Database: 16})
+			},
+			wantErrs: []string{
+				`redis database "cache": database index -1 out of range [0, 15]`,
+				`redis database "sessions": database index 16 out of range [0, 15]`,
+			},
+		},
+		{
+			name: "configured_range",
+			modify: func(cfg *Runtime) {
+				cfg.RedisDatabases[0].Database = 16
+			},
+			databases: 32,
+		},
+		{
+			name: "collision",
+			modify: func(cfg *Runtime) {
+				db := *cfg.RedisDatabases[0]
+				db.EncoreName = "sessions"
+				cfg.RedisDatabases = append(cfg.RedisDatabases, &db)
+			},
+			wantErrs: []string{`redis database "sessions": uses the same server and database index 1 as "cache"`},
+		},
+		{
+			name: "shared_index_with_prefixes",
+			modify: func(cfg *Runtime) {
+				db := *cfg.RedisDatabases[0]
+				db.EncoreName = "sessions"
+				db.KeyPrefix = "sessions/"
+				cfg.RedisDatabases = append(cfg.RedisDatabases, &db)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fullRuntime()
+			tt.modify(cfg)
+			errs := cfg.validateRedisDatabases(tt.databases)
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("got errors %v, want %d", errs, len(tt.wantErrs))
+			}
+			for i, want := range tt.wantErrs {
+				if got := errs[i].Error(); got != want {
+					t.Errorf("error %d: got %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+
+	// The range is configurable when parsing.
+	cfg := fullRuntime()
+	cfg.RedisDatabases[0].Database = 20
+	config, err := EncodeRuntime(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseRuntimeOptions(config, WithValidate()); err == nil {
+		t.Error("default range: expected an error, got nil")
+	}
+	if _, err := ParseRuntimeOptions(config, WithValidate(), WithRedisDatabases(32)); err != nil {
+		t.Errorf("configured range: unexpected error: %v", err)
+	}
+}
+
+func TestValidateGateways(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Runtime)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Runtime) {},
+		},
+		{
+			name: "missing_name",
+			modify: func(cfg *Runtime) {
+				cfg.Gateways[0].Name = ""
+			},
+			wantErr: `gateway 0: missing name`,
+		},
+		{
+			name: "overlapping_hosts",
+			modify: func(cfg *Runtime) {
+				cfg.Gateways = append(cfg.Gateways, Gateway{Name: "wildcard", Host: "*.EXAMPLE.com"})
+			},
+			wantErr: `gateway "wildcard": host "*.EXAMPLE.com" overlaps with host "api.example.com" of gateway "api-gateway"`,
+		},
+		{
+			name: "distinct_hosts",
+			modify: func(cfg *Runtime) {
+				cfg.Gateways = append(cfg.Gateways, Gateway{Name: "admin", Host: "admin.example.org"})
+			},
+		},
+		{
+			name: "dangling_service",
+			modify: func(cfg *Runtime) {
+				cfg.Gateways[0].Services = []string{"users", "billing", "orders"}
+			},
+			wantErr: `gateway "api-gateway": service "orders" is not hosted by this instance`,
+		},
+		{
+			name: "discovered_service_not_hosted",
+			modify: func(cfg *Runtime) {
+				cfg.Gateways[0].Services = []string{"users", "billing"}
+			},
+			wantErr: `gateway "api-gateway": service "billing" is not hosted by this instance`,
+		},
+		{
+			name: "hosted_services",
+			modify: func(cfg *Runtime) {
+				cfg.HostedServices = append(cfg.HostedServices, "billing")
+				cfg.Gateways[0].Services = []string{"users", "billing"}
+			},
+		},
+		{
+			name: "unknown_service_without_hosted",
+			modify: func(cfg *Runtime) {
+				cfg.HostedServices = nil
+				cfg.Gateways[0].Services = []string{"billing", "orders"}
+			},
+			wantErr: `gateway "api-gateway": unknown service "orders"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fullRuntime()
+			tt.modify(cfg)
+			err := errors.Join(cfg.validateGateways()...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePubsubTopics(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Runtime)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Runtime) {},
+		},
+		{
+			name: "duplicate_name",
+			modify: func(cfg *Runtime) {
+				topic := *cfg.PubsubTopics["signups"]
+				cfg.PubsubTopics["signups-copy"] = &topic
+			},
+			wantErr: `pubsub topic "signups": defined more than once (keys ["signups" "signups-copy"])`,
+		},
+		{
+			name: "dangling_provider",
+			modify: func(cfg *Runtime) {
+				cfg.PubsubTopics["signups"].ProviderID = 1
+			},
+			wantErr: `pubsub topic "signups": unknown provider id 1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fullRuntime()
+			tt.modify(cfg)
+			err := errors.Join(cfg.validatePubsubTopics()...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRuntimeDuplicatePubsubTopics(t *testing.T) {
+	data := `{"api_base_url": "https://example.com", "pubsub_providers": [{}], "pubsub_topics": {
+		"a": {"encore_name": "a"},
+		"b": {"encore_name": "b"},
+		"a": {"encore_name": "a"},
+		"b": {"encore_name": "b"}
+	}}`
+	config := base64.StdEncoding.EncodeToString([]byte(data))
+
+	// Duplicates are only looked for when validating, keeping the last one.
+	if _, err := ParseRuntimeErr(config, ""); err != nil {
+		t.Fatalf("ParseRuntimeErr: %v", err)
+	}
+
+	_, err := ParseRuntimeValidated(config, "")
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Stage != StageValidate {
+		t.Fatalf("got error %v, want a validate-stage *ParseError", err)
+	}
+	for _, want := range []string{`pubsub topic "a": defined more than once`, `pubsub topic "b": defined more than once`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	// A topic referencing a provider that doesn't exist is rejected too.
+	data = `{"api_base_url": "https://example.com", "pubsub_topics": {"a": {"encore_name": "a", "provider_id": 0}}}`
+	if _, err := ParseRuntimeErr(base64.StdEncoding.EncodeToString([]byte(data)), ""); err == nil ||
+		!strings.Contains(err.Error(), `pubsub topic "a": unknown provider id 0`) {
+		t.Errorf("dangling provider: got %v, want an unknown provider id error", err)
+	}
+}
+
+func TestParseRuntimeStrictDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "top_level",
+			data: `{"api_base_url": "https://a.example.com", "app_id": "app", "api_base_url": "https://b.example.com"}`,
+			want: []string{`duplicate key "api_base_url"`},
+		},
+		{
+			name: "nested",
+			data: `{"api_base_url": "https://example.com", "sql_servers": [{"host": "a"}, {"host": "b", "host": "c"}],
+				"cors": {"debug": true, "debug": false}}`,
+			want: []string{`duplicate key "sql_servers[1].host"`, `duplicate key "cors.debug"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := base64.StdEncoding.EncodeToString([]byte(tt.data))
+			_, err := ParseRuntimeStrict(config, "")
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Stage != StageValidate {
+				t.Fatalf("got error %v, want a validate-stage *ParseError", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+
+			// Outside of strict mode the last value wins, as before,
+			// even when validating.
+			if _, err := ParseRuntimeErr(config, ""); err != nil {
+				t.Errorf("non-strict: unexpected error: %v", err)
+			}
+			if _, err := ParseRuntimeValidated(config, ""); err != nil && strings.Contains(err.Error(), "duplicate key") {
+				t.Errorf("validated: unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateAPIBaseURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr string
+	}{
+		{url: "https://api.example.com"},
+		{url: "http://localhost:4000/prefix"},
+		{url: "", wantErr: "missing api base url"},
+		{url: "api.example.com", wantErr: `api base url "api.example.com": missing scheme`},
+		{url: "https://", wantErr: `api base url "https://": missing host`},
+		{url: "ftp://api.example.com", wantErr: `unsupported scheme "ftp"`},
+		{url: "https://[::1", wantErr: `api base url "https://[::1"`},
+	}
+	for _, tt := range tests {
+		err := validateAPIBaseURL(tt.url)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tt.url, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%q: got error %v, want it to contain %q", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWithExpectedDatabases(t *testing.T) {
+	config, err := EncodeRuntime(fullRuntime())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		expected []string
+		wantErr  []string
+	}{
+		{name: "exact_match", expected: []string{"users"}},
+		{name: "unexpected_extra", expected: []string{}, wantErr: []string{`sql database "users": not expected`}},
+		{
+			name:     "missing_expected",
+			expected: []string{"users", "orders"},
+			wantErr:  []string{`sql database "orders": expected but missing`},
+		},
+		{
+			name:     "both",
+			expected: []string{"orders"},
+			wantErr:  []string{`sql database "users": not expected`, `sql database "orders": expected but missing`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRuntimeOptions(config, WithExpectedDatabases(tt.expected...))
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Stage != StageValidate {
+				t.Fatalf("got error %v, want a validate-stage *ParseError", err)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import "time"
+
+// RuntimeView is a read-only view of the runtime config, implemented
+// by *Runtime, for consumers that only need to read a few values.
+// Depending on it rather than on *Runtime lets tests supply fakes.
+//
+// Since Runtime exposes most values as fields and a type can't have
+// a field and a method of the same name, the accessors for those
+// fields are named after the field with a "Get" prefix.
+type RuntimeView interface {
+	GetAppID() string
+	GetAPIBaseURL() string
+	GetEnvName() string
+	GetEnvType() string
+	GetEnvCloud() string
+	GetDeployID() string
+
+	IsLocalDev() bool
+	IsEphemeral() bool
+
+	SQLDatabase(encoreName string) (*SQLDatabase, bool)
+	RedisDatabase(encoreName string) (*RedisDatabase, bool)
+	Gateway(name string) (*Gateway, bool)
+	HostedServiceNames() []string
+	DiscoveryTarget(service string) (string, bool)
+	Subscriptions(topic string) []*PubsubSubscription
+
+	FlagString(name string) (string, bool)
+	FlagBool(name string) (bool, bool)
+	RequestTimeout() (time.Duration, bool)
+}
+
+var _ RuntimeView = (*Runtime)(nil)
+
+// GetAppID returns r.AppID.
+func (r *Runtime) GetAppID() string { return r.AppID }
+
+// GetAPIBaseURL returns r.APIBaseURL.
+func (r *Runtime) GetAPIBaseURL() string { return r.APIBaseURL }
+
+// GetEnvName returns r.EnvName.
+func (r *Runtime) GetEnvName() string { return r.EnvName }
+
+// GetEnvType returns r.EnvType.
+func (r *Runtime) GetEnvType() string { return r.EnvType }
+
+// GetEnvCloud returns r.EnvCloud.
+func (r *Runtime) GetEnvCloud() string { return r.EnvCloud }
+
+// GetDeployID returns r.DeployID.
+func (r *Runtime) GetDeployID() string { return r.DeployID }
@@ -0,0 +1,37 @@
+package config
+
+import "testing"
+
+// fakeView is a RuntimeView that overrides a few values,
+// deferring to an embedded *Runtime for the rest.
+type fakeView struct {
+	*Runtime
+	baseURL string
+}
+
+func (f fakeView) GetAPIBaseURL() string { return f.baseURL }
+
+// describeDB is an example consumer that only needs a RuntimeView.
+func describeDB(v RuntimeView, name string) string {
+	db, ok := v.SQLDatabase(name)
+	if !ok {
+		return v.GetEnvName() + ": no database " + name
+	}
+	return v.GetEnvName() + ": " + db.DatabaseName + " via " + v.GetAPIBaseURL()
+}
+
+func TestRuntimeView(t *testing.T) {
+	cfg := fullRuntime()
+	if got, want := describeDB(cfg, "users"), cfg.EnvName+": users-db via "+cfg.APIBaseURL; got != want {
+		t.Errorf("runtime: got %q, want %q", got, want)
+	}
+
+	fake := fakeView{Runtime: &Runtime{EnvName: "fake"}, baseURL: "http://fake"}
+	if got, want := describeDB(fake, "users"), "fake: no database users"; got != want {
+		t.Errorf("fake: got %q, want %q", got, want)
+	}
+	fake.SQLDatabases = []*SQLDatabase{{EncoreName: "users", DatabaseName: "fake-db"}}
+	if got, want := describeDB(fake, "users"), "fake: fake-db via http://fake"; got != want {
+		t.Errorf("fake: got %q, want %q", got, want)
+	}
+}
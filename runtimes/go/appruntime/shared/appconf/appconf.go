@@ -26,8 +26,5 @@ func init() {
 		encoreenv.Set(k, v)
 	}
 
-	Runtime = config.ParseRuntime(
-		encoreenv.Get("ENCORE_RUNTIME_CONFIG"),
-		encoreenv.Get("ENCORE_DEPLOY_ID"),
-	)
+	Runtime = config.Load()
 }
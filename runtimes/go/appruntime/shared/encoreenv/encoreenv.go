@@ -13,6 +13,16 @@ func Set(env, val string) {
 	envs[env] = val
 }
 
+// Environ returns the ENCORE_ environment variables in the
+// form "key=value", in the same format as [os.Environ].
+func Environ() []string {
+	environ := make([]string, 0, len(envs))
+	for key, val := range envs {
+		environ = append(environ, key+"="+val)
+	}
+	return environ
+}
+
 var envs map[string]string
 
 func init() {
@@ -79,41 +79,13 @@ type processTimings struct {
 }
 
 func timingsFromConfig(runtime *config.Runtime) processTimings {
-	// Setup the config
-	var cfg config.GracefulShutdownTimings
-	if runtime.GracefulShutdown != nil {
-		cfg = *runtime.GracefulShutdown
+	phases := runtime.ShutdownPhases()
+	return processTimings{
+		cancelRunningTasksAfter: phases.CancelHandlersAfter,
+		forceCloseTasksGrace:    config.ForceCloseTasksGrace,
+		forceShutdownAfter:      phases.ForceShutdownAfter,
+		forceShutdownGrace:      config.ForceShutdownGrace,
 	}
-
-	t := processTimings{
-		forceCloseTasksGrace: 1 * time.Second,
-		forceShutdownGrace:   1 * time.Second,
-	}
-
-	// Handle the migration from ShutdownTimout to GracefulShutdown configuration
-	if cfg.Total == nil {
-		t.forceShutdownAfter = runtime.ShutdownTimeout
-		if t.forceShutdownAfter <= 0 {
-			t.forceShutdownAfter = 5 * time.Second
-		}
-	} else {
-		t.forceShutdownAfter = *cfg.Total - t.forceShutdownGrace
-		if t.forceShutdownAfter <= 0 {
-			t.forceShutdownAfter = 500 * time.Millisecond
-		}
-	}
-
-	// Get the handler timeout
-	if cfg.Handlers == nil {
-		t.cancelRunningTasksAfter = t.forceShutdownAfter - t.forceCloseTasksGrace
-	} else {
-		t.cancelRunningTasksAfter = *cfg.Handlers
-	}
-	if t.cancelRunningTasksAfter < 0 {
-		t.cancelRunningTasksAfter = 0
-	}
-
-	return t
 }
 
 // WatchForShutdownSignals watches for shutdown signals (SIGTERM, SIGINT)
@@ -115,11 +115,7 @@ func dbConf(srv *config.SQLServer, db *config.SQLDatabase) (*pgxpool.Config, err
 		uri += " host=" + srv.Host // hostname
 	}
 
-	if srv.ServerCACert != "" {
-		uri += " sslmode=verify-ca"
-	} else {
-		uri += " sslmode=prefer"
-	}
+	uri += " sslmode=" + srv.SSLMode()
 
 	cfg, err := pgxpool.ParseConfig(uri)
 	if err != nil {
@@ -127,10 +123,9 @@ func dbConf(srv *config.SQLServer, db *config.SQLDatabase) (*pgxpool.Config, err
 	}
 
 	// Set the pool size based on the config.
-	cfg.MaxConns = 30
-	if n := db.MaxConnections; n > 0 {
-		cfg.MaxConns = int32(n)
-	}
+	pool := db.PoolConfig()
+	cfg.MaxConns = int32(pool.MaxOpenConns)
+	cfg.MaxConnLifetime = pool.ConnMaxLifetime
 
 	// If we have a server CA, set it in the TLS config.
 	if srv.ServerCACert != "" {